@@ -1,14 +1,32 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"arc-scanner/internal/autoscan"
 	"arc-scanner/internal/config"
+	"arc-scanner/internal/debugcapture"
+	"arc-scanner/internal/gridscan"
+	"arc-scanner/internal/health"
+	"arc-scanner/internal/icons"
+	"arc-scanner/internal/identify"
+	"arc-scanner/internal/inventory"
 	"arc-scanner/internal/items"
 	"arc-scanner/internal/keyboard"
 	"arc-scanner/internal/scanner"
@@ -22,15 +40,64 @@ import (
 var Version = "dev"
 
 type App struct {
-	ctx     context.Context
-	scanner scanner.Scanner
-	matcher *items.Matcher
-	repo    *items.Repository
-	updater *updater.Updater
+	ctx      context.Context
+	settings config.Settings
+	scanner  scanner.Scanner
+	repo     *items.Repository
+	icons    *icons.Cache
+	updater  *updater.Updater
+	debug    *debugcapture.Recorder // nil unless debug captures are on
+	autoScan *autoscan.Watcher
+
+	snapshots *inventory.Store   // nil if the app data directory is unusable
+	inventory *inventory.Service // bound to the frontend
+
+	// baseItems is the item data as loaded from its source; the matcher
+	// and index are rebuilt from it whenever overrides change.
+	baseItems   []items.Item
+	itemsOrigin string
+
+	// gameDisplay is the display the last scan happened on. The overlay
+	// follows it so it shows on the game's monitor. displayMu is held
+	// while the overlay moves, so moves from concurrent scans don't
+	// interleave.
+	displayMu   sync.Mutex
+	gameDisplay image.Rectangle
+
+	// cancelScan cancels the scan in flight, so a new key press doesn't
+	// wait for one that hangs.
+	scanMu     sync.Mutex
+	cancelScan context.CancelFunc
+
+	mu        sync.RWMutex
+	matcher   *items.Matcher
+	itemsMap  items.ItemMap
+	iconIndex *icons.Index   // nil until icons are synced or with icon matching off
+	health    *health.Report // nil until the OCR self-test has run
 }
 
 func NewApp() *App {
-	return &App{}
+	app := &App{}
+
+	if err := migrateLinuxDirs(); err != nil {
+		slog.Warn("failed to migrate data to the XDG directories", "error", err)
+	}
+
+	// The icon cache must exist before the asset server starts serving,
+	// and the snapshot service before it is bound
+	appDataDir, err := getAppDataDir()
+	if err == nil {
+		app.icons = icons.NewCache(filepath.Join(appDataDir, "icons"))
+
+		if app.snapshots, err = inventory.NewStore(filepath.Join(appDataDir, "snapshots")); err != nil {
+			slog.Warn("inventory snapshots disabled", "error", err)
+		}
+	}
+	app.inventory = inventory.NewService(app.snapshots, appDataDir, func() config.InventorySettings {
+		return app.settings.Inventory
+	})
+
+	return app
 }
 
 func (a *App) startup(ctx context.Context) {
@@ -43,6 +110,25 @@ func (a *App) startup(ctx context.Context) {
 		slog.Error("failed to initialize window", "error", err)
 	}
 
+	if err := a.loadSettings(); err != nil {
+		slog.Warn("failed to load settings, using defaults", "error", err)
+	}
+
+	if a.settings.Debug.Enabled {
+		if err := a.initDebug(); err != nil {
+			slog.Warn("failed to enable debug captures", "error", err)
+		}
+	}
+
+	// Before the items, which the scanner's vocabulary is built from
+	s := scanner.New(a.settings.Scanner)
+	screenWidth, screenHeight := robotgo.GetScreenSize()
+	s.SetCursorSpace(image.Pt(screenWidth, screenHeight))
+	a.scanner = s
+
+	// The binary, version and language checks don't need the items
+	defer func() { go a.checkHealth() }()
+
 	itemsList, err := a.initItems()
 	if err != nil {
 		slog.Error("failed to initialize items", "error", err)
@@ -50,8 +136,13 @@ func (a *App) startup(ctx context.Context) {
 		return
 	}
 
-	a.scanner = scanner.New()
-	a.matcher = items.NewMatcher(itemsList)
+	a.baseItems = itemsList
+	if err := a.applyOverrides(); err != nil {
+		slog.Warn("failed to apply overrides, using items without them", "error", err)
+		a.setItems(items.Overrides{}.Apply(a.baseItems, a.itemsOrigin))
+	}
+
+	a.initAutoScan(ctx)
 
 	// Initialize updater
 	a.updater = updater.New("LealKevin", "Arc-Scanner", Version)
@@ -59,11 +150,19 @@ func (a *App) startup(ctx context.Context) {
 	// Check for updates in background
 	go a.checkForUpdates()
 
-	a.initKeyboardHook(ctx, itemsList)
+	go a.syncIcons(itemsList)
+
+	a.initKeyboardHook(ctx)
 
 	slog.Info("application started", "items", len(itemsList), "version", Version)
 }
 
+func (a *App) shutdown(ctx context.Context) {
+	if closer, ok := a.scanner.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
 func (a *App) checkForUpdates() {
 	// Wait for frontend to be ready before checking
 	time.Sleep(500 * time.Millisecond)
@@ -91,6 +190,55 @@ func (a *App) checkForUpdates() {
 	runtime.EventsEmit(a.ctx, "update-available", info)
 }
 
+// syncIcons downloads missing item icons and removes ones no longer used.
+func (a *App) syncIcons(itemsList []items.Item) {
+	if a.icons == nil {
+		return
+	}
+
+	urls := make([]string, 0, len(itemsList))
+	for _, item := range itemsList {
+		if item.Icon != "" {
+			urls = append(urls, item.Icon)
+		}
+	}
+
+	if err := a.icons.Sync(a.ctx, urls); err != nil {
+		slog.Warn("icon sync incomplete", "error", err)
+		return
+	}
+
+	if _, err := a.icons.Prune(urls); err != nil {
+		slog.Warn("failed to prune icon cache", "error", err)
+	}
+
+	if a.settings.Scanner.IconMatching {
+		a.buildIconIndex(itemsList)
+	}
+}
+
+// buildIconIndex fingerprints the cached item icons for icon matching.
+func (a *App) buildIconIndex(itemsList []items.Item) {
+	index, err := identify.IconIndex(a.icons, itemsList)
+	if err != nil {
+		slog.Warn("failed to save icon fingerprints", "error", err)
+	}
+
+	a.mu.Lock()
+	a.iconIndex = index
+	a.mu.Unlock()
+}
+
+// serveAsset handles asset server requests that aren't part of the
+// frontend bundle, i.e. locally cached icons.
+func (a *App) serveAsset(w http.ResponseWriter, r *http.Request) {
+	if a.icons == nil {
+		http.NotFound(w, r)
+		return
+	}
+	a.icons.ServeHTTP(w, r)
+}
+
 func (a *App) initWindow(ctx context.Context) error {
 	runtime.WindowSetAlwaysOnTop(ctx, true)
 
@@ -107,7 +255,17 @@ func (a *App) initWindow(ctx context.Context) error {
 		}
 	}
 
-	// Fallback: use first screen if IsCurrent not found (Windows compatibility)
+	// Fallback: use the primary screen, then the first one, if IsCurrent
+	// is not found (Windows compatibility). The overlay moves to the game's
+	// display on the first scan.
+	if currentScreen.Size.Width == 0 {
+		for _, screen := range screens {
+			if screen.IsPrimary {
+				currentScreen = screen
+				break
+			}
+		}
+	}
 	if currentScreen.Size.Width == 0 && len(screens) > 0 {
 		currentScreen = screens[0]
 	}
@@ -129,6 +287,23 @@ func (a *App) initWindow(ctx context.Context) error {
 	return nil
 }
 
+func (a *App) loadSettings() error {
+	a.settings = config.DefaultSettings()
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	settings, err := config.LoadSettings(filepath.Join(configDir, "settings.json"))
+	if err != nil {
+		return err
+	}
+
+	a.settings = settings
+	return nil
+}
+
 func (a *App) initItems() ([]items.Item, error) {
 	appDataDir, err := getAppDataDir()
 	if err != nil {
@@ -139,38 +314,166 @@ func (a *App) initItems() ([]items.Item, error) {
 		return nil, fmt.Errorf("failed to create app data directory: %w", err)
 	}
 
-	cachePath := filepath.Join(appDataDir, "items.json")
+	cachePath := filepath.Join(appDataDir, "items.cache")
 	a.repo = items.NewRepository(cachePath)
 
 	if a.repo.CacheExists() {
 		slog.Info("loading items from cache")
-		itemsList, err := a.repo.LoadFromCache()
+		itemsList, origin, err := a.repo.LoadFromCache()
 		if err == nil {
+			a.itemsOrigin = origin
 			return itemsList, nil
 		}
-		slog.Warn("cache load failed, fetching from API", "error", err)
+		slog.Warn("cache load failed, trying item sources", "error", err)
 	}
 
-	slog.Info("fetching items from API")
-	itemsList, err := a.repo.FetchFromAPI()
+	source, err := items.NewSourceChain(a.settings.ItemSources)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item sources: %w", err)
+	}
+
+	// Migrate the JSON cache used by older versions
+	legacyPath := filepath.Join(appDataDir, "items.json")
+	var legacy items.ItemSource
+	if _, err := os.Stat(legacyPath); err == nil {
+		legacy = items.NewFileSource(legacyPath)
+		source = items.NewChainSource(legacy, source)
+	}
+
+	itemsList, err := source.Load(a.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch items: %w", err)
 	}
+	a.itemsOrigin = usedSource(source).Name()
 
-	if err := a.repo.SaveToCache(itemsList); err != nil {
+	// Don't cache snapshot data so the next launch retries the network
+	if snapshot, ok := usedSource(source).(*items.EmbeddedSource); ok {
+		slog.Warn("using embedded item snapshot", "generatedAt", snapshot.GeneratedAt())
+		go a.reportStaleItems(snapshot.GeneratedAt())
+		return itemsList, nil
+	}
+
+	if err := a.repo.SaveToCache(itemsList, a.itemsOrigin); err != nil {
 		slog.Warn("failed to save cache", "error", err)
+		return itemsList, nil
+	}
+
+	// Only drop the legacy file once its items live on in the cache
+	if legacy != nil && usedSource(source) == legacy {
+		if err := os.Remove(legacyPath); err != nil {
+			slog.Warn("failed to remove legacy items file", "error", err)
+		}
 	}
 
 	return itemsList, nil
 }
 
-func (a *App) initKeyboardHook(ctx context.Context, itemsList []items.Item) {
-	itemsMap := items.BuildIndex(itemsList)
-	hook := keyboard.New(ctx)
+// usedSource unwraps nested chains to the source that actually
+// supplied the items.
+func usedSource(source items.ItemSource) items.ItemSource {
+	for {
+		chain, ok := source.(*items.ChainSource)
+		if !ok || chain.Used() == nil {
+			return source
+		}
+		source = chain.Used()
+	}
+}
+
+// StaleItemsInfo tells the frontend that item values come from the
+// embedded snapshot and how old it is.
+type StaleItemsInfo struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	AgeDays     int       `json:"ageDays"`
+}
 
-	hook.Register(config.ScanKey, func() {
-		a.handleScan(itemsMap)
+func (a *App) reportStaleItems(generatedAt time.Time) {
+	if generatedAt.IsZero() {
+		return
+	}
+
+	// Wait for frontend to be ready before emitting
+	time.Sleep(500 * time.Millisecond)
+
+	runtime.EventsEmit(a.ctx, "items-stale", StaleItemsInfo{
+		GeneratedAt: generatedAt,
+		AgeDays:     int(time.Since(generatedAt).Hours() / 24),
 	})
+}
+
+// applyOverrides merges the user's overrides file onto baseItems and
+// swaps in a fresh matcher and index. On error the current items are
+// kept.
+func (a *App) applyOverrides() error {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	overrides, err := items.LoadOverrides(findOverridesPath(configDir))
+	if err != nil {
+		return err
+	}
+
+	a.setItems(overrides.Apply(a.baseItems, a.itemsOrigin))
+	return nil
+}
+
+// setItems swaps in a matcher and index for merged.
+func (a *App) setItems(merged []items.Item) {
+	a.mu.Lock()
+	a.matcher = items.NewMatcher(merged)
+	a.itemsMap = items.BuildIndex(merged)
+	a.mu.Unlock()
+
+	a.updateVocabulary(merged)
+}
+
+// updateVocabulary regenerates the item words and patterns Tesseract's
+// dictionary is extended with, so OCR follows every change to the items
+func (a *App) updateVocabulary(itemsList []items.Item) {
+	s, ok := a.scanner.(interface {
+		SetVocabulary(dir string, words, patterns []string) error
+	})
+	if !ok {
+		return
+	}
+
+	appDataDir, err := getAppDataDir()
+	if err != nil {
+		slog.Warn("failed to get app data directory", "error", err)
+		return
+	}
+
+	words, patterns := items.Vocabulary(itemsList)
+	if err := s.SetVocabulary(filepath.Join(appDataDir, "tesseract"), words, patterns); err != nil {
+		slog.Warn("failed to update OCR vocabulary", "error", err)
+		return
+	}
+	slog.Debug("OCR vocabulary updated", "words", len(words), "patterns", len(patterns))
+}
+
+// findOverridesPath returns the first overrides file present in dir,
+// preferring YAML. Defaults to overrides.json.
+func findOverridesPath(dir string) string {
+	for _, name := range []string{"overrides.yaml", "overrides.yml", "overrides.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, "overrides.json")
+}
+
+func (a *App) initKeyboardHook(ctx context.Context) {
+	hook := keyboard.New(ctx)
+
+	hook.Register(config.ScanKey, a.handleScan)
+	hook.Register(config.RescanKey, a.handleRescan)
+
+	hook.Register(config.GridScanKey, a.handleGridScan)
+
+	hook.Register(config.AutoScanKey, a.toggleAutoScan)
 
 	hook.Register(config.ToggleKey, func() {
 		slog.Debug("toggling overlay visibility")
@@ -180,46 +483,416 @@ func (a *App) initKeyboardHook(ctx context.Context, itemsList []items.Item) {
 	hook.Start()
 }
 
-func (a *App) handleScan(itemsMap items.ItemMap) {
+// initAutoScan sets up hands-free scanning, started right away when
+// enabled in the settings.
+func (a *App) initAutoScan(ctx context.Context) {
+	a.autoScan = autoscan.New(ctx, a.settings.AutoScan, autoscan.Hooks{
+		Cursor: robotgo.Location,
+		Capture: func(x, y int) (image.Image, error) {
+			return a.scanner.Probe(ctx, x, y)
+		},
+		Present: func(img image.Image) bool {
+			_, ok := scanner.DetectTooltip(img)
+			return ok
+		},
+		// Runs on the watcher's goroutine, so the watcher waits for it
+		// and counts its CPU time, OCR workers included
+		Scan: func() {
+			ctx, cancel := a.beginScan()
+			defer cancel()
+			a.scan(ctx, a.scanner.Scan)
+		},
+		ChildCPU: scanner.ChildCPUTime,
+	})
+	if a.settings.AutoScan.Enabled {
+		a.autoScan.Start()
+	}
+}
+
+func (a *App) toggleAutoScan() {
+	enabled := a.autoScan.Toggle()
+	runtime.EventsEmit(a.ctx, "auto-scan", enabled)
+}
+
+// scanFunc is Scanner.Scan or one of its variants.
+type scanFunc func(ctx context.Context, x, y int, score scanner.Scorer) (scanner.ScanResult, error)
+
+func (a *App) handleScan() {
+	a.startScan(a.scanner.Scan)
+}
+
+// handleRescan scans without reusing a cached read, for when the cache
+// returned a wrong item.
+func (a *App) handleRescan() {
+	a.startScan(a.scanner.Rescan)
+}
+
+// startScan runs a scan off the keyboard hook's goroutine, so the hook
+// keeps delivering key presses while it runs.
+func (a *App) startScan(scan scanFunc) {
+	ctx, cancel := a.beginScan()
+	go func() {
+		defer cancel()
+		a.scan(ctx, scan)
+	}()
+}
+
+// beginScan cancels the scan in flight, if any, and returns the context
+// of the next one.
+func (a *App) beginScan() (context.Context, context.CancelFunc) {
+	a.scanMu.Lock()
+	defer a.scanMu.Unlock()
+
+	if a.cancelScan != nil {
+		a.cancelScan()
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelScan = cancel
+	return ctx, cancel
+}
+
+// withScanDeadline bounds ctx by the per-scan deadline from the settings.
+func (a *App) withScanDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.settings.Scanner.ScanTimeoutMs <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(a.settings.Scanner.ScanTimeoutMs)*time.Millisecond)
+}
+
+// scanFailed logs a failed scan and tells the overlay what went wrong
+// and how to fix it. Scans cancelled by a newer one are expected and stay
+// quiet. It returns the decision to record in debug captures.
+func (a *App) scanFailed(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		slog.Debug("scan cancelled", "error", err)
+		return debugcapture.DecisionCancelled
+	case isTimeout(err):
+		slog.Warn("scan timed out", "error", err, "deadlineMs", a.settings.Scanner.ScanTimeoutMs)
+		runtime.EventsEmit(a.ctx, "scan-timeout", a.settings.Scanner.ScanTimeoutMs)
+		runtime.EventsEmit(a.ctx, "scan-error", a.scanError(err))
+		return debugcapture.DecisionTimeout
+	default:
+		slog.Error("scan failed", "error", err)
+		runtime.EventsEmit(a.ctx, "scan-error", a.scanError(err))
+		return debugcapture.DecisionError
+	}
+}
+
+// Codes sent with the scan-error event.
+const (
+	codeTesseractMissing = "tesseract-missing"
+	codeTessdataMissing  = "tessdata-missing"
+	codeCaptureDenied    = "capture-denied"
+	codeOCRTimeout       = "ocr-timeout"
+	codeEmptyOCR         = "empty-ocr"
+	codeNoTooltip        = "no-tooltip"
+	codeScanFailed       = "scan-failed"
+)
+
+// ScanError is the payload of the scan-error event.
+type ScanError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint"`
+}
+
+// scanError maps a scanner error to what the overlay shows: whether to
+// reinstall, grant a permission or recalibrate.
+func (a *App) scanError(err error) ScanError {
+	switch {
+	case errors.Is(err, scanner.ErrTesseractMissing):
+		return ScanError{codeTesseractMissing, "Tesseract OCR is not installed", health.InstallFix()}
+	case errors.Is(err, scanner.ErrTessdataMissing):
+		var dir string
+		if s, ok := a.scanner.(selfTester); ok {
+			dir = s.Setup().TessdataPath
+		}
+		return ScanError{codeTessdataMissing, "Tesseract language data is missing", health.LanguageFix(config.TesseractLang, dir)}
+	case errors.Is(err, scanner.ErrCaptureDenied), errors.Is(err, scanner.ErrBlankCapture) && screenCaptureDenied():
+		return ScanError{codeCaptureDenied, "The screen can't be captured", health.CaptureFix()}
+	case errors.Is(err, scanner.ErrBlankCapture):
+		return ScanError{codeNoTooltip, "No tooltip found next to the cursor",
+			"Hover an item until its tooltip is shown, then scan again."}
+	case isTimeout(err):
+		return ScanError{codeOCRTimeout, "Scan timed out",
+			"OCR is too slow on this machine. Raise scanner.scanTimeoutMs in settings.json, " +
+				"or lower scanner.adaptiveBudgetMs."}
+	case errors.Is(err, scanner.ErrEmptyOCR):
+		return ScanError{codeEmptyOCR, "No text found next to the cursor",
+			"Hover the item until its tooltip is fully shown. If it is, set scanner.uiScale in " +
+				"settings.json to match the in-game UI scale."}
+	default:
+		return ScanError{codeScanFailed, "Scan failed",
+			"Try again. If it keeps failing, enable debug captures and include the latest scan folder in a bug report."}
+	}
+}
+
+// screenCaptureDenied reports whether macOS denies the Screen Recording
+// permission, which makes every capture blank. Elsewhere a blank capture
+// is just a uniform frame, e.g. a loading screen.
+func screenCaptureDenied() bool {
+	permissions := checkPermissions()
+	return permissions == 1 || permissions == 3
+}
+
+// isTimeout reports whether err comes from the scan deadline or the OCR
+// pool's own timeout.
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, scanner.ErrOCRTimeout)
+}
+
+func (a *App) scan(ctx context.Context, scan scanFunc) {
 	startTime := time.Now()
 	x, y := robotgo.Location()
 
 	slog.Debug("scanning", "x", x, "y", y)
 
-	img, err := a.scanner.TakeScreenshot(x, y)
-	if err != nil {
-		slog.Error("screenshot failed", "error", err)
-		return
-	}
+	record := debugcapture.Scan{Time: startTime, Cursor: image.Pt(x, y)}
+	defer a.saveDebugScan(&record)
 
-	text, err := a.scanner.ProcessImage(img)
+	a.mu.RLock()
+	matcher, itemsMap := a.matcher, a.itemsMap
+	a.mu.RUnlock()
+
+	ctx, cancel := a.withScanDeadline(ctx)
+	defer cancel()
+
+	result, err := scan(ctx, x, y, matcher.Score)
+	record.Raw, record.Processed, record.Slot = result.Raw, result.Processed, result.Slot
+	record.Tooltip = result.Tooltip
+	record.Cached = result.Cached
+	record.Timings = scanTimings(result.Timings)
 	if err != nil {
-		slog.Error("OCR failed", "error", err)
+		record.Decision, record.Error = a.scanFailed(err), err.Error()
 		return
 	}
+	a.followGameDisplay(result.Display)
+	text := result.Text
 
 	tokens := items.CleanOCRText(text)
-	item, err := a.matcher.FindItem(tokens)
+	record.Text, record.Tokens = text, tokens
+	record.Variant, record.Confidence = result.Variant, result.Confidence
+
+	matchStart := time.Now()
+	item, source, err := a.identify(result, matcher, itemsMap)
+	record.Timings["match"] = milliseconds(time.Since(matchStart))
 	if err != nil {
 		slog.Debug("item not found", "tokens", tokens)
+		record.Decision = debugcapture.DecisionNotFound
 		runtime.EventsEmit(a.ctx, "scan-failed", nil)
 		return
 	}
+	record.ItemID, record.Source = item.ID, source
+
+	if item.Hidden {
+		slog.Debug("item hidden by override", "id", item.ID)
+		record.Decision = debugcapture.DecisionHidden
+		runtime.EventsEmit(a.ctx, "item-hidden", item.Name)
+		return
+	}
 
-	quantity := items.ParseQuantity(text)
+	quantity := items.ParseQuantityWords(result.OCRResult)
+	record.Decision, record.Quantity = debugcapture.DecisionFound, quantity
 	slog.Info("item found",
 		"name", item.Name,
 		"value", item.Value,
+		"valueOrigin", item.Origin.Value,
 		"quantity", quantity,
+		"variant", result.Variant,
+		"confidence", result.Confidence,
+		"source", source,
+		"cached", result.Cached,
 		"duration", time.Since(startTime))
 
 	if item.RecycleComponents != nil {
 		logRecycleInfo(item, itemsMap)
 	}
 
+	item.Icon = a.localIcon(item.Icon)
+
 	runtime.EventsEmit(a.ctx, "item-found", item)
 }
 
+// handleGridScan identifies every occupied slot of the inventory grid
+// under the cursor and sends the overlay the stash value and its
+// cheapest stacks.
+func (a *App) handleGridScan() {
+	ctx, cancel := a.beginScan()
+	go func() {
+		defer cancel()
+		a.gridScan(ctx)
+	}()
+}
+
+func (a *App) gridScan(ctx context.Context) {
+	startTime := time.Now()
+	x, y := robotgo.Location()
+
+	slog.Debug("scanning inventory grid", "x", x, "y", y)
+
+	a.mu.RLock()
+	matcher, itemsMap := a.matcher, a.itemsMap
+	a.mu.RUnlock()
+
+	summary, err := gridscan.Scan(ctx, x, y, a.settings.Scanner, gridscan.Hooks{
+		ScanGrid: a.scanner.ScanGrid,
+		ScanTooltip: func(ctx context.Context, x, y int) (scanner.ScanResult, error) {
+			return a.scanner.Scan(ctx, x, y, matcher.Score)
+		},
+		Identify: func(result scanner.ScanResult) (items.Item, string, error) {
+			return a.identify(result, matcher, itemsMap)
+		},
+		MoveCursor: func(x, y int) { robotgo.Move(x, y) },
+		Deadline:   a.withScanDeadline,
+		LocalIcon:  a.localIcon,
+	})
+	if errors.Is(err, scanner.ErrNoGrid) {
+		slog.Debug("no inventory grid under the cursor")
+		runtime.EventsEmit(a.ctx, "scan-failed", nil)
+		return
+	}
+	if err != nil {
+		a.scanFailed(err)
+		return
+	}
+
+	slog.Info("inventory grid scanned",
+		"slots", len(summary.Slots),
+		"unidentified", summary.Unidentified,
+		"totalValue", summary.TotalValue,
+		"duration", time.Since(startTime))
+
+	runtime.EventsEmit(a.ctx, "grid-scanned", summary)
+
+	a.saveSnapshot(summary, startTime)
+}
+
+// saveSnapshot stores a grid scan and sends the overlay what changed
+// over the last raid
+func (a *App) saveSnapshot(summary inventory.Summary, t time.Time) {
+	if a.snapshots == nil {
+		return
+	}
+
+	snapshot, err := a.snapshots.Save(summary, t)
+	if err != nil {
+		slog.Error("failed to save inventory snapshot", "error", err)
+		return
+	}
+	slog.Debug("inventory snapshot saved", "id", snapshot.ID)
+
+	if removed, err := a.snapshots.Prune(a.settings.Inventory.MaxSnapshots); err != nil {
+		slog.Warn("failed to prune inventory snapshots", "error", err)
+	} else if removed > 0 {
+		slog.Debug("inventory snapshots pruned", "removed", removed)
+	}
+
+	diff, err := a.inventory.GetLastRaid()
+	if err != nil {
+		if !errors.Is(err, inventory.ErrSnapshotNotFound) {
+			slog.Error("failed to diff inventory snapshots", "error", err)
+		}
+		return
+	}
+
+	slog.Info("last raid",
+		"from", diff.From,
+		"netValue", diff.NetValue,
+		"gained", len(diff.Gained),
+		"used", len(diff.Used),
+		"lost", len(diff.Lost))
+	runtime.EventsEmit(a.ctx, "raid-diff", diff)
+}
+
+// localIcon points the overlay at the local copy of an icon, so it
+// works offline
+func (a *App) localIcon(url string) string {
+	if a.icons != nil {
+		if local, ok := a.icons.LocalURL(url); ok {
+			return local
+		}
+	}
+	return url
+}
+
+// identify names the item in a scan with the current icon index
+func (a *App) identify(result scanner.ScanResult, matcher *items.Matcher, itemsMap items.ItemMap) (items.Item, string, error) {
+	a.mu.RLock()
+	index := a.iconIndex
+	a.mu.RUnlock()
+	return identify.New(matcher, index, itemsMap).Identify(result)
+}
+
+// initDebug creates the recorder for debug captures in the app data
+// directory
+func (a *App) initDebug() error {
+	appDataDir, err := getAppDataDir()
+	if err != nil {
+		return fmt.Errorf("failed to get app data directory: %w", err)
+	}
+
+	maxBytes := int64(a.settings.Debug.MaxSizeMB) << 20
+	recorder, err := debugcapture.NewRecorder(filepath.Join(appDataDir, "debug"), maxBytes)
+	if err != nil {
+		return err
+	}
+
+	a.debug = recorder
+	slog.Info("debug captures enabled", "dir", recorder.Dir(), "maxSizeMB", a.settings.Debug.MaxSizeMB)
+	return nil
+}
+
+// saveDebugScan writes a scan record in the background when debug
+// captures are on, so the overlay isn't held up by PNG encoding
+func (a *App) saveDebugScan(record *debugcapture.Scan) {
+	if a.debug == nil {
+		return
+	}
+
+	if record.Timings == nil {
+		record.Timings = make(map[string]float64)
+	}
+	record.Timings["total"] = milliseconds(time.Since(record.Time))
+
+	go func(record debugcapture.Scan) {
+		folder, err := a.debug.Save(record)
+		if err != nil {
+			slog.Warn("failed to save debug capture", "error", err)
+			return
+		}
+		slog.Debug("debug capture saved", "folder", folder)
+	}(*record)
+}
+
+func scanTimings(t scanner.Timings) map[string]float64 {
+	return map[string]float64{
+		"capture":    milliseconds(t.Capture),
+		"preprocess": milliseconds(t.Preprocess),
+		"ocr":        milliseconds(t.OCR),
+		"adaptive":   milliseconds(t.Adaptive),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// followGameDisplay moves the overlay to the display the game is on,
+// i.e. the one scans happen on, when it changes
+func (a *App) followGameDisplay(display image.Rectangle) {
+	a.displayMu.Lock()
+	defer a.displayMu.Unlock()
+
+	if display.Empty() || display == a.gameDisplay {
+		return
+	}
+	a.gameDisplay = display
+
+	slog.Info("moving overlay to game display", "display", display)
+	moveWindowToDisplay(a.ctx, display)
+}
+
 func logRecycleInfo(item items.Item, itemsMap items.ItemMap) {
 	totalValue := 0
 	for _, entry := range *item.RecycleComponents {
@@ -245,6 +918,14 @@ func getAppDataDir() (string, error) {
 			appData = filepath.Join(homeDir, "AppData", "Roaming")
 		}
 		appDataDir = filepath.Join(appData, "arc-scanner")
+	} else if goruntime.GOOS == "linux" {
+		// Linux: caches, snapshots and captures follow the XDG base
+		// directory spec, which only allows absolute paths
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if !filepath.IsAbs(dataHome) {
+			dataHome = filepath.Join(homeDir, ".local", "share")
+		}
+		appDataDir = filepath.Join(dataHome, "arc-scanner")
 	} else {
 		// macOS and other Unix-like systems
 		appDataDir = filepath.Join(homeDir, "Library", "Application Support", "arc-scanner")
@@ -253,6 +934,232 @@ func getAppDataDir() (string, error) {
 	return appDataDir, nil
 }
 
+// getConfigDir returns the directory holding settings.json and the
+// overrides file. It is the app data directory everywhere but Linux,
+// where it follows XDG_CONFIG_HOME.
+func getConfigDir() (string, error) {
+	if goruntime.GOOS != "linux" {
+		return getAppDataDir()
+	}
+	configHome, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, "arc-scanner"), nil
+}
+
+// migrateLinuxDirs moves what Linux builds before XDG support kept in
+// ~/Library/Application Support/arc-scanner: settings and overrides to
+// the config directory, everything else to the app data directory.
+// Entries already in the new place are kept, and the old directory is
+// removed once empty, so this runs once.
+func migrateLinuxDirs() error {
+	if goruntime.GOOS != "linux" {
+		return nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	legacyDir := filepath.Join(homeDir, "Library", "Application Support", "arc-scanner")
+	entries, err := os.ReadDir(legacyDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", legacyDir, err)
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+	appDataDir, err := getAppDataDir()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		dir := appDataDir
+		if name == "settings.json" || strings.HasPrefix(name, "overrides.") {
+			dir = configDir
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			slog.Warn("not migrating, already present", "name", name, "dir", dir)
+			continue
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		if err := os.Rename(filepath.Join(legacyDir, name), filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", name, err)
+		}
+		slog.Info("migrated to XDG directory", "name", name, "dir", dir)
+	}
+
+	// Fails while skipped entries are left, and also drops the empty
+	// Library directories that only existed for us
+	for dir := legacyDir; dir != homeDir; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// ReloadOverrides re-reads the overrides file from the app data
+// directory and applies it to the loaded items
+func (a *App) ReloadOverrides() error {
+	if a.baseItems == nil {
+		return fmt.Errorf("items not loaded")
+	}
+
+	if err := a.applyOverrides(); err != nil {
+		slog.Error("failed to reload overrides", "error", err)
+		return err
+	}
+
+	slog.Info("overrides reloaded")
+	return nil
+}
+
+// ExportItemsJSON writes the loaded items, with overrides applied, as
+// indented JSON next to the cache and returns the file path
+func (a *App) ExportItemsJSON() (string, error) {
+	appDataDir, err := getAppDataDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get app data directory: %w", err)
+	}
+
+	a.mu.RLock()
+	itemsList := make([]items.Item, 0, len(a.itemsMap))
+	for _, item := range a.itemsMap {
+		itemsList = append(itemsList, item)
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(itemsList, func(i, j int) bool {
+		return itemsList[i].ID < itemsList[j].ID
+	})
+
+	path := filepath.Join(appDataDir, "items-export.json")
+	if err := a.repo.ExportJSON(itemsList, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// PreviewImage is one step of a preprocessing preview, encoded for the
+// frontend
+type PreviewImage struct {
+	Stage   string `json:"stage"`
+	DataURL string `json:"dataUrl"`
+}
+
+// PreviewPreprocessing runs the last capture through the preprocessing
+// pipeline and returns every intermediate image as a PNG data URL.
+// Passing stages tries an alternative pipeline; nil uses the configured one.
+func (a *App) PreviewPreprocessing(stages []config.StageSettings) ([]PreviewImage, error) {
+	previewer, ok := a.scanner.(interface {
+		Preview(stages []config.StageSettings) ([]scanner.PreviewStep, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("scanner does not support previews")
+	}
+
+	steps, err := previewer.Preview(stages)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]PreviewImage, len(steps))
+	for i, step := range steps {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, step.Image); err != nil {
+			return nil, fmt.Errorf("failed to encode %s preview: %w", step.Stage, err)
+		}
+		images[i] = PreviewImage{
+			Stage:   step.Stage,
+			DataURL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		}
+	}
+	return images, nil
+}
+
+// selfTester is the part of the scanner the OCR self-test needs.
+type selfTester interface {
+	Setup() scanner.Setup
+	Identify(ctx context.Context, img image.Image, score scanner.Scorer) (scanner.ScanResult, error)
+}
+
+// checkHealth self-tests the OCR setup by reading the sample tooltip
+// like a scan, and sends the result to the overlay.
+func (a *App) checkHealth() (health.Report, error) {
+	s, ok := a.scanner.(selfTester)
+	if !ok {
+		return health.Report{}, fmt.Errorf("scanner does not support self-tests")
+	}
+
+	setup := s.Setup()
+	report := health.Run(a.ctx, health.Target{
+		Engine:        setup.Engine,
+		TesseractPath: setup.TesseractPath,
+		TessdataPath:  setup.TessdataPath,
+		Language:      config.TesseractLang,
+		Read: func(ctx context.Context, img image.Image) (string, string, error) {
+			a.mu.RLock()
+			matcher := a.matcher
+			a.mu.RUnlock()
+			if matcher == nil {
+				return "", "", health.ErrNoItems
+			}
+
+			result, err := s.Identify(ctx, img, matcher.Score)
+			if err != nil {
+				return "", "", err
+			}
+			item, _, err := matcher.Match(result.OCRResult)
+			if err != nil {
+				return result.Text, "", nil
+			}
+			return result.Text, item.Name, nil
+		},
+	})
+
+	if report.OK {
+		slog.Info("OCR self-test passed", "engine", report.Engine, "version", report.Version)
+	}
+	for _, check := range report.Failed() {
+		slog.Warn("OCR self-test failed", "check", check.Name, "detail", check.Detail, "fix", check.Fix)
+	}
+
+	a.mu.Lock()
+	a.health = &report
+	a.mu.Unlock()
+
+	runtime.EventsEmit(a.ctx, "health", report)
+	return report, nil
+}
+
+// GetHealth returns the result of the OCR self-test, with steps to fix
+// each failed check. It runs the test if it hasn't run yet.
+func (a *App) GetHealth() (health.Report, error) {
+	if a.scanner == nil {
+		return health.Report{}, fmt.Errorf("scanner not initialized")
+	}
+
+	a.mu.RLock()
+	report := a.health
+	a.mu.RUnlock()
+
+	if report != nil {
+		return *report, nil
+	}
+	return a.checkHealth()
+}
+
 // GetVersion returns the current app version
 func (a *App) GetVersion() string {
 	return Version
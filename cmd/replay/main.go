@@ -0,0 +1,225 @@
+// Command replay runs labeled tooltip screenshots through the scan
+// pipeline and reports OCR and matching accuracy. It needs no display,
+// only the tesseract binary.
+//
+//	go run ./cmd/replay -items items-export.json testdata/replay
+//
+// -compare-vocabulary replays once without and once with the item
+// vocabulary Tesseract is given and reports the difference. -icons points
+// at the app's icon cache to identify items by their icons as well.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/icons"
+	"arc-scanner/internal/identify"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/replay"
+	"arc-scanner/internal/scanner"
+)
+
+func main() {
+	itemsPath := flag.String("items", "", "items JSON export or items.cache (required)")
+	overridesPath := flag.String("overrides", "", "overrides file to apply to the items")
+	iconsDir := flag.String("icons", "", "icon cache directory of the app, for icon matching")
+	settingsPath := flag.String("settings", "", "settings.json with the scanner settings to replay")
+	jsonPath := flag.String("json", "", "write the full report as JSON to this file (- for stdout)")
+	compareVocabulary := flag.Bool("compare-vocabulary", false, "also replay without the item vocabulary and report the accuracy gain")
+	verbose := flag.Bool("v", false, "log scanner output")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: replay [flags] <dir with %s>\n", replay.LabelsFile)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *itemsPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	if err := run(flag.Arg(0), *itemsPath, *overridesPath, *iconsDir, *settingsPath, *jsonPath, *compareVocabulary); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, itemsPath, overridesPath, iconsDir, settingsPath, jsonPath string, compareVocabulary bool) error {
+	samples, err := replay.LoadSamples(dir)
+	if err != nil {
+		return err
+	}
+
+	itemsList, origin, err := loadItems(itemsPath)
+	if err != nil {
+		return err
+	}
+	if len(itemsList) == 0 {
+		return fmt.Errorf("no items in %s", itemsPath)
+	}
+	if overridesPath != "" {
+		overrides, err := items.LoadOverrides(overridesPath)
+		if err != nil {
+			return err
+		}
+		itemsList = overrides.Apply(itemsList, origin)
+	}
+
+	settings := config.DefaultSettings()
+	if settingsPath != "" {
+		if settings, err = config.LoadSettings(settingsPath); err != nil {
+			return err
+		}
+	}
+
+	var index *icons.Index
+	if iconsDir != "" && settings.Scanner.IconMatching {
+		if index, err = identify.IconIndex(icons.NewCache(iconsDir), itemsList); err != nil {
+			slog.Warn("failed to save icon fingerprints", "error", err)
+		}
+	}
+
+	var baseline replay.Report
+	if compareVocabulary {
+		without := settings.Scanner
+		without.UserVocabulary = false
+		if baseline, err = replayWith(samples, without, itemsList, index); err != nil {
+			return err
+		}
+		settings.Scanner.UserVocabulary = true
+	}
+
+	report, err := replayWith(samples, settings.Scanner, itemsList, index)
+	if err != nil {
+		return err
+	}
+	printReport(report)
+	if compareVocabulary {
+		printVocabularyGain(baseline, report)
+	}
+
+	if jsonPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if jsonPath == "-" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return os.WriteFile(jsonPath, data, 0o644)
+}
+
+// replayWith runs the samples through a scanner with the given
+// settings, giving it the item vocabulary and identifying items like the
+// app does. index is nil without icon matching.
+func replayWith(samples []replay.Sample, settings config.ScannerSettings, itemsList []items.Item, index *icons.Index) (replay.Report, error) {
+	s := scanner.New(settings)
+	defer s.Close()
+
+	vocabDir, err := os.MkdirTemp("", "replay-vocabulary-*")
+	if err != nil {
+		return replay.Report{}, fmt.Errorf("failed to create vocabulary directory: %w", err)
+	}
+	defer os.RemoveAll(vocabDir)
+
+	words, patterns := items.Vocabulary(itemsList)
+	if err := s.SetVocabulary(vocabDir, words, patterns); err != nil {
+		return replay.Report{}, err
+	}
+
+	matcher := items.NewMatcher(itemsList)
+	opts := replay.Options{
+		TooltipDetection: settings.TooltipDetection,
+		Identify:         identify.New(matcher, index, items.BuildIndex(itemsList)).Identify,
+	}
+	return replay.Run(context.Background(), samples, s, matcher, opts), nil
+}
+
+// loadItems reads an items export or cache and returns the items with
+// the origin the app records for them.
+func loadItems(path string) ([]items.Item, string, error) {
+	if filepath.Ext(path) == ".cache" {
+		return items.NewRepository(path).LoadFromCache()
+	}
+	source := items.NewFileSource(path)
+	itemsList, err := source.Load(context.Background())
+	return itemsList, source.Name(), err
+}
+
+// printVocabularyGain compares a replay without the item vocabulary to
+// one with it.
+func printVocabularyGain(without, with replay.Report) {
+	out := os.Stderr
+
+	fmt.Fprintf(out, "\n%-12s %8s %8s %8s\n", "vocabulary", "without", "with", "gain")
+	rows := []struct {
+		name          string
+		without, with float64
+	}{
+		{"precision", without.Precision, with.Precision},
+		{"recall", without.Recall, with.Recall},
+		{"quantity", without.QuantityAccuracy, with.QuantityAccuracy},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(out, "%-12s %8.3f %8.3f %+8.3f\n", row.name, row.without, row.with, row.with-row.without)
+	}
+	if l, ok := with.Latency["ocr"]; ok {
+		fmt.Fprintf(out, "%-12s %8.1f %8.1f %+8.1f\n", "ocr p50 ms", without.Latency["ocr"].P50, l.P50, l.P50-without.Latency["ocr"].P50)
+	}
+}
+
+func printReport(report replay.Report) {
+	out := os.Stderr
+
+	fmt.Fprintf(out, "samples:   %d\n", report.Samples)
+	fmt.Fprintf(out, "precision: %.3f (%d TP, %d FP)\n", report.Precision, report.TruePositives, report.FalsePositives)
+	fmt.Fprintf(out, "recall:    %.3f (%d TP, %d FN)\n", report.Recall, report.TruePositives, report.FalseNegatives)
+	fmt.Fprintf(out, "quantity:  %.3f\n", report.QuantityAccuracy)
+
+	if len(report.Confusions) > 0 {
+		fmt.Fprintln(out, "\nconfusions (expected -> predicted):")
+		for _, c := range report.Confusions {
+			fmt.Fprintf(out, "  %3d  %s -> %s\n", c.Count, c.Expected, c.Predicted)
+		}
+	}
+
+	stages := make([]string, 0, len(report.Latency))
+	for stage := range report.Latency {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+
+	fmt.Fprintf(out, "\n%-12s %8s %8s %8s %8s\n", "latency ms", "mean", "p50", "p95", "max")
+	for _, stage := range stages {
+		l := report.Latency[stage]
+		fmt.Fprintf(out, "%-12s %8.1f %8.1f %8.1f %8.1f\n", stage, l.Mean, l.P50, l.P95, l.Max)
+	}
+
+	var failures []string
+	for _, r := range report.Results {
+		if r.Error != "" {
+			failures = append(failures, fmt.Sprintf("  %s: %s", filepath.Base(r.Image), r.Error))
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintf(out, "\nerrors:\n%s\n", strings.Join(failures, "\n"))
+	}
+}
@@ -6,11 +6,21 @@ import {
   WindowSetPosition,
   ScreenGetAll,
 } from "../wailsjs/runtime/runtime";
-import type { Item, ItemFoundEvent } from "./types";
+import type {
+  GridSummary as GridSummaryInfo,
+  HealthReport,
+  Item,
+  ItemFoundEvent,
+  RaidDiff,
+  ScanError,
+  StaleItemsInfo,
+} from "./types";
+import { GetLastRaid } from "../wailsjs/go/inventory/Service";
 import { useTimeout } from "./hooks/useTimeout";
 import { ScanStatus } from "./components/ScanStatus";
 import { ItemCard } from "./components/ItemCard";
 import { ItemBadges } from "./components/ItemBadges";
+import { GridSummary } from "./components/GridSummary";
 import { UpdateNotification } from "./components/UpdateNotification";
 
 const WINDOW_WIDTH_VISIBLE = 200;
@@ -22,18 +32,29 @@ function App() {
   const [item, setItem] = useState<Item>();
   const [isScanning, setIsScanning] = useState(false);
   const [isScanningFailed, setIsScanningFailed] = useState(false);
+  const [failedText, setFailedText] = useState<string>();
+  const [failedHint, setFailedHint] = useState<string>();
   const [isVisible, setIsVisible] = useState(true);
   const [showItem, setShowItem] = useState(false);
   const [hasUpdate, setHasUpdate] = useState(false);
+  const [staleItems, setStaleItems] = useState<StaleItemsInfo>();
+  const [gridSummary, setGridSummary] = useState<GridSummaryInfo>();
+  const [lastRaid, setLastRaid] = useState<RaidDiff>();
+  const [notice, setNotice] = useState<string>();
   const hasUpdateRef = useRef(false);
 
   const fadeTimeout = useTimeout();
   const clearTimeout = useTimeout();
   const failedTimeout = useTimeout();
+  const gridTimeout = useTimeout();
+  const noticeTimeout = useTimeout();
 
   const updateWindowSize = useCallback(async (visible: boolean) => {
     const screens = await ScreenGetAll();
-    const currentScreen = screens.find((s) => s.isCurrent) || screens[0];
+    const currentScreen =
+      screens.find((s) => s.isCurrent) ||
+      screens.find((s) => s.isPrimary) ||
+      screens[0];
 
     if (visible) {
       const x = currentScreen.width - WINDOW_WIDTH_VISIBLE;
@@ -53,6 +74,8 @@ function App() {
       fadeTimeout.clear();
       clearTimeout.clear();
       failedTimeout.clear();
+      gridTimeout.clear();
+      setGridSummary(undefined);
 
       updateWindowSize(true);
 
@@ -71,6 +94,37 @@ function App() {
       }, 1500);
     };
 
+    const handleGridScanned = (summary: GridSummaryInfo) => {
+      fadeTimeout.clear();
+      failedTimeout.clear();
+      gridTimeout.clear();
+
+      updateWindowSize(true);
+
+      setItem(undefined);
+      setShowItem(false);
+      setIsScanning(false);
+      setIsScanningFailed(false);
+      setGridSummary(summary);
+
+      gridTimeout.set(() => {
+        setGridSummary(undefined);
+        // Only shrink window if no update is pending
+        if (!hasUpdateRef.current) {
+          updateWindowSize(false);
+        }
+      }, 6000);
+    };
+
+    const handleRaidDiff = (diff: RaidDiff) => {
+      setLastRaid(diff);
+    };
+
+    // Two snapshots from earlier sessions already make a raid
+    GetLastRaid()
+      .then(setLastRaid)
+      .catch(() => {});
+
     const handleScanStarted = () => {
       fadeTimeout.clear();
       clearTimeout.clear();
@@ -81,20 +135,54 @@ function App() {
       setIsScanningFailed(false);
     };
 
-    const handleScanFailed = () => {
+    const handleScanFailed = (text?: string, hint?: string) => {
       fadeTimeout.clear();
       clearTimeout.clear();
       setItem(undefined);
       setShowItem(false);
       updateWindowSize(true);
       setIsScanningFailed(true);
-      failedTimeout.set(() => {
-        setIsScanningFailed(false);
+      setFailedText(text);
+      setFailedHint(hint);
+      failedTimeout.set(
+        () => {
+          setIsScanningFailed(false);
+          // Only shrink window if no update is pending
+          if (!hasUpdateRef.current) {
+            updateWindowSize(false);
+          }
+        },
+        // Leave time to read the fix
+        hint ? 8000 : 2000,
+      );
+    };
+
+    const handleItemHidden = (name: string) => {
+      handleScanFailed(`${name} is hidden`, "Remove it from your overrides file to show it again.");
+    };
+
+    const handleScanError = (error: ScanError) => {
+      handleScanFailed(error.message, error.hint);
+    };
+
+    const handleHealth = (report: HealthReport) => {
+      if (!report.ok) {
+        const failed = report.checks.find((check) => !check.ok);
+        handleScanFailed(`OCR problem: ${failed?.name ?? "unknown"}`, failed?.fix);
+      }
+    };
+
+    const handleAutoScan = (enabled: boolean) => {
+      noticeTimeout.clear();
+      updateWindowSize(true);
+      setNotice(enabled ? "Auto-scan on" : "Auto-scan off");
+      noticeTimeout.set(() => {
+        setNotice(undefined);
         // Only shrink window if no update is pending
         if (!hasUpdateRef.current) {
           updateWindowSize(false);
         }
-      }, 2000);
+      }, 1500);
     };
 
     const handleToggleVisibility = () => {
@@ -107,29 +195,72 @@ function App() {
       updateWindowSize(true); // Expand window to show notification
     };
 
+    const handleItemsStale = (info: StaleItemsInfo) => {
+      setStaleItems(info);
+    };
+
     const unsubItemFound = EventsOn("item-found", handleItemFound);
+    const unsubGridScanned = EventsOn("grid-scanned", handleGridScanned);
+    const unsubRaidDiff = EventsOn("raid-diff", handleRaidDiff);
     const unsubScanStarted = EventsOn("scan-started", handleScanStarted);
-    const unsubScanFailed = EventsOn("scan-failed", handleScanFailed);
+    const unsubScanFailed = EventsOn("scan-failed", () => handleScanFailed());
+    const unsubScanError = EventsOn("scan-error", handleScanError);
+    const unsubItemHidden = EventsOn("item-hidden", handleItemHidden);
+    const unsubHealth = EventsOn("health", handleHealth);
+    const unsubAutoScan = EventsOn("auto-scan", handleAutoScan);
     const unsubToggle = EventsOn("toggle-visibility", handleToggleVisibility);
     const unsubUpdate = EventsOn("update-available", handleUpdateAvailable);
+    const unsubStale = EventsOn("items-stale", handleItemsStale);
 
     return () => {
       unsubItemFound();
+      unsubGridScanned();
+      unsubRaidDiff();
       unsubScanStarted();
       unsubScanFailed();
+      unsubScanError();
+      unsubItemHidden();
+      unsubHealth();
+      unsubAutoScan();
       unsubToggle();
       unsubUpdate();
+      unsubStale();
     };
-  }, [updateWindowSize, fadeTimeout, clearTimeout, failedTimeout]);
+  }, [
+    updateWindowSize,
+    fadeTimeout,
+    clearTimeout,
+    failedTimeout,
+    gridTimeout,
+    noticeTimeout,
+  ]);
 
   return (
     <div id="app">
       <div className={`container ${isVisible ? "visible" : "hidden"}`}>
-        <ScanStatus isScanning={isScanning} isFailed={isScanningFailed} />
+        <ScanStatus
+          isScanning={isScanning}
+          isFailed={isScanningFailed}
+          failedText={failedText}
+          failedHint={failedHint}
+          notice={notice}
+        />
+        {gridSummary && (
+          <GridSummary
+            summary={gridSummary}
+            lastRaid={lastRaid}
+            className="fade-in"
+          />
+        )}
         {item && (
           <>
             <ItemCard item={item} className={showItem ? "fade-in" : ""} />
             <ItemBadges itemId={item.id} className={showItem ? "fade-in" : ""} />
+            {staleItems && (
+              <div className="stale-notice">
+                Offline data, {staleItems.ageDays} days old
+              </div>
+            )}
           </>
         )}
       </div>
@@ -0,0 +1,42 @@
+import type { GridSummary as Summary, RaidDiff } from "../types";
+
+type Props = {
+  summary: Summary;
+  lastRaid?: RaidDiff;
+  className?: string;
+};
+
+export function GridSummary({ summary, lastRaid, className }: Props) {
+  const lowest = summary.lowest.map((i) => summary.slots[i]);
+
+  return (
+    <div className={`grid-summary ${className ?? ""}`}>
+      <div className="grid-total">
+        $ {summary.totalValue}
+        <span className="grid-count">
+          {" "}
+          ({summary.slots.length} slots
+          {summary.unidentified > 0 && `, ${summary.unidentified} unknown`})
+        </span>
+      </div>
+      {lastRaid && (
+        <div className={`grid-raid ${lastRaid.netValue < 0 ? "loss" : "profit"}`}>
+          Last raid: {lastRaid.netValue < 0 ? "-" : "+"}$ {Math.abs(lastRaid.netValue)}
+        </div>
+      )}
+      {lowest.map((slot) => (
+        <div className="grid-slot lowest" key={`${slot.row}-${slot.col}`}>
+          {slot.icon && <img className="grid-slot-icon" src={slot.icon} alt="" />}
+          <span className="grid-slot-name">
+            {slot.name}
+            {slot.quantity > 1 && ` x${slot.quantity}`}
+          </span>
+          <span className="grid-slot-position">
+            R{slot.row + 1} C{slot.col + 1}
+          </span>
+          <span className="grid-slot-value">$ {slot.value * slot.quantity}</span>
+        </div>
+      ))}
+    </div>
+  );
+}
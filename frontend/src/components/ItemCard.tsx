@@ -6,10 +6,15 @@ type Props = {
 };
 
 export function ItemCard({ item, className }: Props) {
+  const isOverridden = item.origin?.value === "override";
+
   return (
-    <div className={`item-card ${className ?? ""}`}>
+    <div className={`item-card ${className ?? ""}`} title={item.notes}>
       <img className="item-icon" src={item.icon} alt={item.name} />
-      <span className="item-value">$ {item.value}</span>
+      <span className={`item-value ${isOverridden ? "overridden" : ""}`}>
+        $ {item.value}
+        {isOverridden && <span className="item-value-origin"> (user)</span>}
+      </span>
     </div>
   );
 }
@@ -1,14 +1,31 @@
 type Props = {
   isScanning: boolean;
   isFailed: boolean;
+  failedText?: string;
+  failedHint?: string;
+  notice?: string;
 };
 
-export function ScanStatus({ isScanning, isFailed }: Props) {
+export function ScanStatus({
+  isScanning,
+  isFailed,
+  failedText = "Scanning failed",
+  failedHint,
+  notice,
+}: Props) {
   if (isScanning) {
     return <div className="scan-status">Scanning...</div>;
   }
   if (isFailed) {
-    return <div className="scan-status failed">Scanning failed</div>;
+    return (
+      <div className="scan-status failed">
+        {failedText}
+        {failedHint && <div className="scan-hint">{failedHint}</div>}
+      </div>
+    );
+  }
+  if (notice) {
+    return <div className="scan-status">{notice}</div>;
   }
   return null;
 }
@@ -1,12 +1,25 @@
+export type FieldOrigins = {
+  name?: string;
+  value?: string;
+  notes?: string;
+};
+
 export type Item = {
   id: string;
   name: string;
   value: number;
   icon: string;
+  notes?: string;
+  origin?: FieldOrigins;
 };
 
 export type ItemFoundEvent = Item;
 
+export type StaleItemsInfo = {
+  generatedAt: string;
+  ageDays: number;
+};
+
 export type UpdateInfo = {
   version: string;
   url: string;
@@ -14,3 +27,65 @@ export type UpdateInfo = {
   releaseNotes: string;
   publishedAt: string;
 };
+
+export type PreviewImage = {
+  stage: string;
+  dataUrl: string;
+};
+
+export type InventorySlot = {
+  row: number;
+  col: number;
+  itemId: string;
+  name: string;
+  icon?: string;
+  quantity: number;
+  value: number;
+  source: string;
+};
+
+export type GridSummary = {
+  slots: InventorySlot[];
+  totalValue: number;
+  unidentified: number;
+  lowest: number[];
+};
+
+export type ItemChange = {
+  itemId: string;
+  name: string;
+  before: number;
+  after: number;
+  delta: number;
+  value: number;
+};
+
+export type RaidDiff = {
+  from: string;
+  to: string;
+  gained: ItemChange[];
+  used: ItemChange[];
+  lost: ItemChange[];
+  netValue: number;
+};
+
+export type HealthCheck = {
+  name: string;
+  ok: boolean;
+  detail: string;
+  fix?: string;
+};
+
+export type HealthReport = {
+  ok: boolean;
+  time: string;
+  engine: string;
+  version?: string;
+  checks: HealthCheck[];
+};
+
+export type ScanError = {
+  code: string;
+  message: string;
+  hint: string;
+};
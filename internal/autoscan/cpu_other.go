@@ -0,0 +1,18 @@
+//go:build !windows
+
+package autoscan
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and system time used by this process's
+// threads. Child processes are counted by Hooks.ChildCPU instead.
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
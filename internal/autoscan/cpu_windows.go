@@ -0,0 +1,27 @@
+//go:build windows
+
+package autoscan
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and kernel time used by this process's
+// threads. Child processes are counted by Hooks.ChildCPU instead.
+func processCPUTime() time.Duration {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(process, &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+	return filetimeDuration(kernel) + filetimeDuration(user)
+}
+
+// filetimeDuration converts a FILETIME span, in 100ns units.
+func filetimeDuration(ft syscall.Filetime) time.Duration {
+	return time.Duration(int64(ft.HighDateTime)<<32|int64(ft.LowDateTime)) * 100
+}
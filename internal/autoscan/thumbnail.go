@@ -0,0 +1,50 @@
+package autoscan
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// Thumbnail size and the mean difference in gray levels above which two
+// captures count as different. A tooltip opening or changing moves the
+// mean far more than capture noise or an animated background behind the
+// cursor.
+const (
+	thumbnailWidth  = 48
+	thumbnailHeight = 36
+	changeThreshold = 4
+)
+
+// thumbnail is a tiny grayscale copy of a capture for cheap change checks.
+type thumbnail []uint8
+
+func newThumbnail(img image.Image) thumbnail {
+	gray := imaging.Resize(imaging.Grayscale(img), thumbnailWidth, thumbnailHeight, imaging.Box)
+
+	t := make(thumbnail, 0, thumbnailWidth*thumbnailHeight)
+	for y := 0; y < thumbnailHeight; y++ {
+		row := gray.Pix[y*gray.Stride:]
+		for x := 0; x < thumbnailWidth; x++ {
+			t = append(t, row[x*4])
+		}
+	}
+	return t
+}
+
+// differs reports whether the mean absolute difference between two
+// thumbnails exceeds changeThreshold.
+func (t thumbnail) differs(other thumbnail) bool {
+	if len(t) != len(other) {
+		return true
+	}
+	sum := 0
+	for i := range t {
+		d := int(t[i]) - int(other[i])
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return sum > changeThreshold*len(t)
+}
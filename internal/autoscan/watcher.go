@@ -0,0 +1,207 @@
+package autoscan
+
+import (
+	"context"
+	"image"
+	"log/slog"
+	"sync"
+	"time"
+
+	"arc-scanner/internal/config"
+)
+
+// moveTolerance is how far, in cursor coordinates, the cursor may drift
+// and still count as resting.
+const moveTolerance = 4
+
+// Hooks connect a Watcher to the screen and the scanner.
+type Hooks struct {
+	// Cursor returns the cursor position.
+	Cursor func() (x, y int)
+
+	// Capture grabs the area a tooltip would appear in next to the
+	// cursor. It should be much cheaper than a scan.
+	Capture func(x, y int) (image.Image, error)
+
+	// Present reports whether a capture shows a tooltip.
+	Present func(img image.Image) bool
+
+	// Scan runs a full scan at the cursor.
+	Scan func()
+
+	// ChildCPU returns the CPU time used so far by processes the scanner
+	// started and has waited for, e.g. tesseract. Optional.
+	ChildCPU func() time.Duration
+}
+
+// Watcher scans hands-free: once the cursor has rested for the dwell
+// time, it captures the tooltip area and scans when a tooltip is shown
+// that differs from the last one scanned. Between checks it sleeps long
+// enough that the CPU time used meanwhile stays under the cap.
+type Watcher struct {
+	ctx   context.Context
+	hooks Hooks
+
+	dwell    time.Duration
+	interval time.Duration
+	maxCPU   float64 // fraction of one core, 0 for no cap
+
+	// cpuTime returns the CPU time used so far by the process and the
+	// OCR processes it started
+	cpuTime func() time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // set while running
+}
+
+// watch is the state of one run of a Watcher.
+type watch struct {
+	cursor     image.Point
+	stillSince time.Time
+	last       thumbnail // previous capture at the resting position
+	scanned    thumbnail // capture of the last scan
+	done       bool      // scanned at the resting position
+}
+
+func New(ctx context.Context, settings config.AutoScanSettings, hooks Hooks) *Watcher {
+	maxCPU := min(float64(settings.MaxCPUPercent)/100, 1)
+	if maxCPU < 0 {
+		maxCPU = 0
+	}
+	cpuTime := processCPUTime
+	if hooks.ChildCPU != nil {
+		cpuTime = func() time.Duration { return processCPUTime() + hooks.ChildCPU() }
+	}
+	return &Watcher{
+		ctx:      ctx,
+		hooks:    hooks,
+		dwell:    time.Duration(settings.DwellMs) * time.Millisecond,
+		interval: time.Duration(settings.IntervalMs) * time.Millisecond,
+		maxCPU:   maxCPU,
+		cpuTime:  cpuTime,
+	}
+}
+
+// Start begins watching the cursor. It does nothing when already running.
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	w.cancel = cancel
+	go w.run(ctx)
+	slog.Info("auto-scan started", "dwell", w.dwell, "maxCPU", w.maxCPU)
+}
+
+// Stop stops watching the cursor.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	w.cancel = nil
+	slog.Info("auto-scan stopped")
+}
+
+// Toggle starts or stops watching and returns whether it is now running.
+func (w *Watcher) Toggle() bool {
+	if w.Running() {
+		w.Stop()
+		return false
+	}
+	w.Start()
+	return true
+}
+
+func (w *Watcher) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cancel != nil
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	var state watch
+	state.reset(image.Point{}, time.Now())
+
+	for {
+		start, startCPU := time.Now(), w.cpuTime()
+		w.step(&state, start)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.wait(time.Since(start), w.cpuTime()-startCPU)):
+		}
+	}
+}
+
+// wait returns how long to sleep after a check that took busy and used
+// cpu across all threads and OCR processes, so that the check and the
+// sleep together average at most maxCPU of one core. Work other code
+// did in the meantime, like a manual scan, counts as well.
+func (w *Watcher) wait(busy, cpu time.Duration) time.Duration {
+	if w.maxCPU == 0 {
+		return w.interval
+	}
+	idle := time.Duration(float64(cpu)/w.maxCPU) - busy
+	return max(w.interval, idle)
+}
+
+// step runs one check. Captures are only taken once the cursor has
+// rested for the dwell time, and a scan only runs when the capture has
+// settled since the previous check, shows a tooltip and isn't the one
+// scanned last.
+func (w *Watcher) step(state *watch, now time.Time) {
+	x, y := w.hooks.Cursor()
+	cursor := image.Pt(x, y)
+	if moved(cursor, state.cursor) {
+		state.reset(cursor, now)
+		return
+	}
+	if state.done || now.Sub(state.stillSince) < w.dwell {
+		return
+	}
+
+	img, err := w.hooks.Capture(x, y)
+	if err != nil {
+		slog.Debug("auto-scan capture failed", "error", err)
+		return
+	}
+
+	thumb := newThumbnail(img)
+	settled := state.last != nil && !thumb.differs(state.last)
+	state.last = thumb
+	switch {
+	case !settled:
+		return
+	case state.scanned != nil && !thumb.differs(state.scanned):
+		state.done = true
+		return
+	case !w.hooks.Present(img):
+		return
+	}
+
+	slog.Debug("auto-scan triggered", "x", x, "y", y)
+	w.hooks.Scan()
+	state.scanned = thumb
+	state.done = true
+}
+
+// reset starts a new resting period at cursor.
+func (s *watch) reset(cursor image.Point, now time.Time) {
+	s.cursor = cursor
+	s.stillSince = now
+	s.last = nil
+	s.done = false
+}
+
+func moved(a, b image.Point) bool {
+	d := a.Sub(b)
+	return d.X*d.X+d.Y*d.Y > moveTolerance*moveTolerance
+}
@@ -0,0 +1,197 @@
+package autoscan
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// screen is a fake desktop: a cursor and whatever the tooltip area shows.
+type screen struct {
+	cursor   image.Point
+	tooltip  int // 0 for none, otherwise which tooltip is shown
+	captures int
+	scans    int
+}
+
+func (s *screen) hooks() Hooks {
+	return Hooks{
+		Cursor: func() (int, int) { return s.cursor.X, s.cursor.Y },
+		Capture: func(x, y int) (image.Image, error) {
+			s.captures++
+			img := imaging.New(300, 200, color.NRGBA{90, 110, 80, 255})
+			if s.tooltip > 0 {
+				shade := uint8(10 + 40*s.tooltip)
+				img = imaging.Paste(img, imaging.New(150, 180, color.NRGBA{shade, shade, shade, 255}), image.Pt(150, 10))
+			}
+			return img, nil
+		},
+		Present: func(img image.Image) bool { return s.tooltip > 0 },
+		Scan:    func() { s.scans++ },
+	}
+}
+
+func TestWatcher_Step(t *testing.T) {
+	type tick struct {
+		at      time.Duration
+		cursor  image.Point
+		tooltip int
+	}
+	tests := []struct {
+		name     string
+		ticks    []tick
+		captures int
+		scans    int
+	}{
+		{
+			name: "scans once after dwell",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 1},
+				{200 * time.Millisecond, image.Pt(100, 100), 1}, // dwelling
+				{400 * time.Millisecond, image.Pt(101, 100), 1}, // first capture
+				{550 * time.Millisecond, image.Pt(100, 101), 1}, // settled, scan
+				{700 * time.Millisecond, image.Pt(100, 100), 1}, // done
+				{900 * time.Millisecond, image.Pt(100, 100), 1},
+			},
+			captures: 2,
+			scans:    1,
+		},
+		{
+			name: "moving cursor never captures",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 1},
+				{300 * time.Millisecond, image.Pt(120, 100), 1},
+				{600 * time.Millisecond, image.Pt(140, 100), 1},
+				{900 * time.Millisecond, image.Pt(160, 100), 1},
+			},
+		},
+		{
+			name: "no tooltip keeps checking",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 0},
+				{400 * time.Millisecond, image.Pt(100, 100), 0},
+				{550 * time.Millisecond, image.Pt(100, 100), 0},
+				{700 * time.Millisecond, image.Pt(100, 100), 0},
+			},
+			captures: 3,
+		},
+		{
+			name: "waits for the tooltip to settle",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 0},
+				{400 * time.Millisecond, image.Pt(100, 100), 0},
+				{550 * time.Millisecond, image.Pt(100, 100), 1}, // just opened
+				{700 * time.Millisecond, image.Pt(100, 100), 1},
+			},
+			captures: 3,
+			scans:    1,
+		},
+		{
+			name: "same tooltip after moving back is not rescanned",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 1},
+				{400 * time.Millisecond, image.Pt(100, 100), 1},
+				{550 * time.Millisecond, image.Pt(100, 100), 1}, // scan
+				{700 * time.Millisecond, image.Pt(300, 100), 1},
+				{1100 * time.Millisecond, image.Pt(300, 100), 1},
+				{1250 * time.Millisecond, image.Pt(300, 100), 1}, // same capture
+			},
+			captures: 4,
+			scans:    1,
+		},
+		{
+			name: "next item is scanned",
+			ticks: []tick{
+				{0, image.Pt(100, 100), 1},
+				{400 * time.Millisecond, image.Pt(100, 100), 1},
+				{550 * time.Millisecond, image.Pt(100, 100), 1}, // scan
+				{700 * time.Millisecond, image.Pt(300, 100), 2},
+				{1100 * time.Millisecond, image.Pt(300, 100), 2},
+				{1250 * time.Millisecond, image.Pt(300, 100), 2}, // scan
+			},
+			captures: 4,
+			scans:    2,
+		},
+	}
+
+	settings := config.AutoScanSettings{DwellMs: 400, IntervalMs: 150, MaxCPUPercent: 25}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &screen{}
+			w := New(context.Background(), settings, s.hooks())
+
+			start := time.Now()
+			var state watch
+			state.reset(image.Point{}, start)
+			for _, tick := range tt.ticks {
+				s.cursor, s.tooltip = tick.cursor, tick.tooltip
+				w.step(&state, start.Add(tick.at))
+			}
+
+			if s.captures != tt.captures || s.scans != tt.scans {
+				t.Errorf("captures, scans = %d, %d, want %d, %d", s.captures, s.scans, tt.captures, tt.scans)
+			}
+		})
+	}
+}
+
+func TestWatcher_Wait(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		maxCPU int
+		busy   time.Duration
+		cpu    time.Duration
+		want   time.Duration
+	}{
+		{25, 10 * ms, 10 * ms, 150 * ms},    // cheap check, interval wins
+		{25, 300 * ms, 300 * ms, 900 * ms},  // single-threaded scan, cap wins
+		{25, 300 * ms, 900 * ms, 3300 * ms}, // OCR fanned out over 3 cores
+		{25, 300 * ms, 30 * ms, 150 * ms},   // waiting on the screen, little CPU
+		{10, 30 * ms, 30 * ms, 270 * ms},
+		{0, 300 * ms, 900 * ms, 150 * ms}, // no cap
+	}
+
+	for _, tt := range tests {
+		settings := config.AutoScanSettings{IntervalMs: 150, MaxCPUPercent: tt.maxCPU}
+		w := New(context.Background(), settings, Hooks{})
+		if got := w.wait(tt.busy, tt.cpu); got != tt.want {
+			t.Errorf("wait(%v, %v) at %d%% = %v, want %v", tt.busy, tt.cpu, tt.maxCPU, got, tt.want)
+		}
+	}
+}
+
+func TestWatcher_CPUTime(t *testing.T) {
+	child := 2 * time.Second
+	w := New(context.Background(), config.AutoScanSettings{}, Hooks{
+		ChildCPU: func() time.Duration { return child },
+	})
+
+	// Burn some CPU on this process's own threads
+	before := w.cpuTime()
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+	child += time.Second
+
+	if used := w.cpuTime() - before; used < time.Second+10*time.Millisecond {
+		t.Errorf("cpu used = %v, want the child's second plus the busy loop", used)
+	}
+}
+
+func TestWatcher_Toggle(t *testing.T) {
+	s := &screen{}
+	w := New(context.Background(), config.AutoScanSettings{IntervalMs: 1}, s.hooks())
+
+	if !w.Toggle() || !w.Running() {
+		t.Fatal("Toggle did not start the watcher")
+	}
+	if w.Toggle() || w.Running() {
+		t.Fatal("Toggle did not stop the watcher")
+	}
+}
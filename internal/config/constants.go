@@ -1,14 +1,33 @@
 package config
 
 const (
+	// The capture boxes below are in reference pixels: the size they have
+	// on a display this many pixels tall. They are scaled to the actual
+	// display at scan time.
+	ReferenceHeight = 1080
+
 	// OCR capture area dimensions (relative to mouse cursor position)
 	OcrBoxWidth   = 450
 	OcrBoxHeight  = 480
 	OcrBoxYOffset = 400
 	OcrBoxXOffset = 0
 
-	ScanKey   = 'y'
-	ToggleKey = 'u'
+	// Wider area captured around the cursor for tooltip detection. The
+	// OcrBox above is cropped from it when no tooltip is found.
+	DetectBoxWidth   = 900
+	DetectBoxHeight  = 720
+	DetectBoxXOffset = 450 // pixels left of the cursor
+	DetectBoxYOffset = 480 // pixels above the cursor
+
+	// Inventory slot under the cursor, centered on it. Its icon is
+	// matched against the item icons.
+	SlotBoxSize = 80
+
+	ScanKey     = 'y'
+	RescanKey   = 'Y' // Shift+Y scans again without the result cache
+	ToggleKey   = 'u'
+	GridScanKey = 'o'
+	AutoScanKey = 'j'
 
 	MetaForgeAPIBase = "https://metaforge.app/api/arc-raiders/items"
 	APIPageSize      = 100
@@ -17,6 +36,7 @@ const (
 	TesseractPSM       = "3" // Fully automatic page segmentation
 	TesseractOEM       = "1" // LSTM only (faster)
 	TesseractWhitelist = "0123456789/' ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	TesseractLang      = "eng"
 
 	ContrastLevel = 20
 	SharpenLevel  = 20
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Item source types accepted in SourceSettings.Type.
+const (
+	SourceMetaForge = "metaforge"
+	SourceFile      = "file"
+	SourceMirror    = "mirror"
+	SourceEmbedded  = "embedded"
+)
+
+// OCR engines accepted in ScannerSettings.OCREngine.
+const (
+	OCREngineAuto = "auto"
+	OCREngineLib  = "libtesseract"
+	OCREngineExec = "exec"
+)
+
+// Settings holds user-tunable options read from settings.json in the
+// app data directory. Fields missing from the file keep their defaults;
+// lists in the file replace the default lists whole.
+type Settings struct {
+	// ItemSources is the prioritized list of places to load item data
+	// from. Each source is tried in order until one succeeds.
+	ItemSources []SourceSettings `json:"itemSources"`
+
+	Scanner ScannerSettings `json:"scanner"`
+
+	AutoScan AutoScanSettings `json:"autoScan"`
+
+	Inventory InventorySettings `json:"inventory"`
+
+	Debug DebugSettings `json:"debug"`
+}
+
+// InventorySettings controls the snapshots saved by grid scans and the
+// raid diff computed from them.
+type InventorySettings struct {
+	// MaxSnapshots is how many snapshots are kept. The oldest are
+	// deleted once it is exceeded.
+	MaxSnapshots int `json:"maxSnapshots"`
+
+	// RaidGapMinutes is how much earlier than the newest snapshot the
+	// one it is diffed against must be, so scanning the stash twice in
+	// a row doesn't count as a raid.
+	RaidGapMinutes int `json:"raidGapMinutes"`
+}
+
+// DebugEnvVar enables debug captures when set to anything but "0" or
+// "false", overriding settings.json.
+const DebugEnvVar = "ARC_SCANNER_DEBUG"
+
+// DebugSettings controls saving every scan's images and OCR output.
+type DebugSettings struct {
+	Enabled bool `json:"enabled"`
+
+	// MaxSizeMB caps the debug folder. The oldest scans are deleted
+	// once it is exceeded.
+	MaxSizeMB int `json:"maxSizeMB"`
+}
+
+// AutoScanSettings controls scanning without a key press once the
+// cursor rests over a tooltip.
+type AutoScanSettings struct {
+	// Enabled starts auto-scan with the app. AutoScanKey toggles it.
+	Enabled bool `json:"enabled"`
+
+	// DwellMs is how long the cursor has to rest before the tooltip area
+	// is checked. IntervalMs is the time between checks.
+	DwellMs    int `json:"dwellMs"`
+	IntervalMs int `json:"intervalMs"`
+
+	// MaxCPUPercent caps the CPU time of the app and its tesseract
+	// processes while auto-scan runs, as a share of one core, so the
+	// game keeps its frame rate. 0 turns the cap off.
+	MaxCPUPercent int `json:"maxCpuPercent"`
+}
+
+// ScannerSettings tunes the capture and OCR pipeline.
+type ScannerSettings struct {
+	// OCREngine selects how Tesseract is run: in-process via
+	// libtesseract, by spawning the CLI, or "auto" to prefer the former.
+	OCREngine string `json:"ocrEngine"`
+
+	// OCRWorkers is the number of OCR workers, each with its own engine.
+	// A libtesseract worker keeps its model loaded; a CLI worker starts
+	// a tesseract process per image. Zero runs OCR directly on the
+	// scanning goroutine.
+	OCRWorkers int `json:"ocrWorkers"`
+
+	// OCRTimeoutMs bounds a single OCR call in the worker pool. Workers
+	// that exceed it are replaced.
+	OCRTimeoutMs int `json:"ocrTimeoutMs"`
+
+	// ScanTimeoutMs is the deadline of a whole scan, from capture to the
+	// last OCR variant. Zero means no deadline.
+	ScanTimeoutMs int `json:"scanTimeoutMs"`
+
+	// TooltipDetection captures a wider area and crops it to the
+	// tooltip panel instead of using the fixed OcrBox.
+	TooltipDetection bool `json:"tooltipDetection"`
+
+	// IconMatching also identifies the hovered slot by comparing its
+	// icon against the cached item icons, which helps when the tooltip
+	// text is unreadable.
+	IconMatching bool `json:"iconMatching"`
+
+	// GridHoverOCR makes an inventory grid scan hover every slot its
+	// icon can't identify and read the tooltip, moving the mouse.
+	// GridHoverDelayMs is how long to wait for the tooltip to appear.
+	GridHoverOCR     bool `json:"gridHoverOcr"`
+	GridHoverDelayMs int  `json:"gridHoverDelayMs"`
+
+	// ScanCacheSize is how many recent scan results are kept and reused
+	// when the same tooltip is captured again. Zero disables the cache.
+	// ScanCacheTolerance is how many bits of a capture's hash, one per
+	// 3x3 reference pixels, may differ for it to count as the same tooltip.
+	ScanCacheSize      int `json:"scanCacheSize"`
+	ScanCacheTolerance int `json:"scanCacheTolerance"`
+
+	// UserVocabulary adds the item names, tier suffixes and stack counts
+	// to Tesseract's dictionary. The penalties are Tesseract's cost for
+	// words outside the dictionary and for dictionary words that aren't
+	// frequent; raising the first favours item names over lookalikes.
+	UserVocabulary         bool    `json:"userVocabulary"`
+	NonDictWordPenalty     float64 `json:"nonDictWordPenalty"`
+	NonFreqDictWordPenalty float64 `json:"nonFreqDictWordPenalty"`
+
+	// UIScale overrides how many capture coordinates one reference pixel
+	// spans. Zero derives it from the display height.
+	UIScale float64 `json:"uiScale"`
+
+	// Preprocessing is the ordered list of image stages applied to the
+	// capture before OCR.
+	Preprocessing []StageSettings `json:"preprocessing"`
+
+	// AdaptiveThreshold is the score (OCR confidence times match score,
+	// 0-1) below which a first pass is retried with every variant in
+	// Variants. Zero disables adaptive OCR.
+	AdaptiveThreshold float64 `json:"adaptiveThreshold"`
+
+	// AdaptiveBudgetMs bounds how long the variants may run. Variants
+	// still running when it expires don't get a vote.
+	AdaptiveBudgetMs int `json:"adaptiveBudgetMs"`
+
+	// Variants are alternative preprocessing pipelines for tooltips the
+	// default pipeline reads poorly.
+	Variants []VariantSettings `json:"variants"`
+}
+
+// VariantSettings is a named alternative preprocessing pipeline.
+type VariantSettings struct {
+	Name          string          `json:"name"`
+	Preprocessing []StageSettings `json:"preprocessing"`
+}
+
+// StageSettings configures one preprocessing stage. Params holds the
+// stage's numeric options; stages that pick between variants, such as
+// binarize (otsu, adaptive) and channel (red, green, blue, max), use Mode.
+type StageSettings struct {
+	Stage  string             `json:"stage"`
+	Mode   string             `json:"mode,omitempty"`
+	Params map[string]float64 `json:"params,omitempty"`
+}
+
+// Param returns the named parameter, or def when it is not set.
+func (s StageSettings) Param(name string, def float64) float64 {
+	if v, ok := s.Params[name]; ok {
+		return v
+	}
+	return def
+}
+
+// SourceSettings describes a single item source.
+type SourceSettings struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"` // file sources
+	URL  string `json:"url,omitempty"`  // metaforge and mirror sources
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		ItemSources: []SourceSettings{
+			{Type: SourceMetaForge, URL: MetaForgeAPIBase},
+			{Type: SourceEmbedded},
+		},
+		Debug: DebugSettings{
+			MaxSizeMB: 200,
+		},
+		Inventory: InventorySettings{
+			MaxSnapshots:   500,
+			RaidGapMinutes: 10,
+		},
+		AutoScan: AutoScanSettings{
+			DwellMs:       400,
+			IntervalMs:    150,
+			MaxCPUPercent: 25,
+		},
+		Scanner: ScannerSettings{
+			OCREngine:          OCREngineAuto,
+			OCRWorkers:         2,
+			OCRTimeoutMs:       5000,
+			ScanTimeoutMs:      4000,
+			TooltipDetection:   true,
+			IconMatching:       true,
+			GridHoverDelayMs:   250,
+			ScanCacheSize:      16,
+			ScanCacheTolerance: 6,
+			// Tesseract's defaults are 0.15 and 0.1
+			UserVocabulary:         true,
+			NonDictWordPenalty:     0.3,
+			NonFreqDictWordPenalty: 0.15,
+			Preprocessing: []StageSettings{
+				{Stage: "grayscale"},
+				{Stage: "invert"},
+				{Stage: "contrast", Params: map[string]float64{"amount": ContrastLevel}},
+				{Stage: "sharpen", Params: map[string]float64{"sigma": SharpenLevel}},
+			},
+			AdaptiveThreshold: 0.6,
+			AdaptiveBudgetMs:  700,
+			Variants: []VariantSettings{
+				{
+					Name: "upscale-otsu",
+					Preprocessing: []StageSettings{
+						{Stage: "grayscale"},
+						{Stage: "invert"},
+						{Stage: "upscale", Params: map[string]float64{"factor": 2}},
+						{Stage: "binarize", Mode: "otsu"},
+					},
+				},
+				{
+					Name: "adaptive",
+					Preprocessing: []StageSettings{
+						{Stage: "grayscale"},
+						{Stage: "invert"},
+						{Stage: "binarize", Mode: "adaptive", Params: map[string]float64{"radius": 15, "offset": 10}},
+					},
+				},
+				{
+					// Rarity-colored names lose contrast in plain grayscale
+					Name: "max-channel",
+					Preprocessing: []StageSettings{
+						{Stage: "channel", Mode: "max"},
+						{Stage: "invert"},
+						{Stage: "contrast", Params: map[string]float64{"amount": 30}},
+						{Stage: "sharpen", Params: map[string]float64{"sigma": SharpenLevel}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// LoadSettings reads settings from path on top of the defaults, then
+// applies environment overrides. A missing file is not an error and
+// yields the defaults.
+func LoadSettings(path string) (Settings, error) {
+	settings, err := readSettings(path)
+	applyEnv(&settings)
+	return settings, err
+}
+
+func readSettings(path string) (Settings, error) {
+	settings := DefaultSettings()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	// Decoding into the default lists would merge each stage, variant
+	// and source the user wrote into the default at the same position,
+	// so decode lists into empty ones and keep the defaults only for
+	// lists the file leaves out.
+	defaults := settings
+	settings.ItemSources = nil
+	settings.Scanner.Preprocessing = nil
+	settings.Scanner.Variants = nil
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return DefaultSettings(), fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	if settings.ItemSources == nil {
+		settings.ItemSources = defaults.ItemSources
+	}
+	if settings.Scanner.Preprocessing == nil {
+		settings.Scanner.Preprocessing = defaults.Scanner.Preprocessing
+	}
+	if settings.Scanner.Variants == nil {
+		settings.Scanner.Variants = defaults.Scanner.Variants
+	}
+
+	return settings, nil
+}
+
+// applyEnv applies overrides from environment variables.
+func applyEnv(settings *Settings) {
+	if v := os.Getenv(DebugEnvVar); v != "" {
+		settings.Debug.Enabled = v != "0" && v != "false"
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSettings_Lists(t *testing.T) {
+	defaults := DefaultSettings()
+
+	tests := []struct {
+		name     string
+		file     string
+		want     []StageSettings
+		variants []VariantSettings
+		sources  []SourceSettings
+	}{
+		{
+			name:     "missing lists keep the defaults",
+			file:     `{"scanner": {"ocrWorkers": 3}}`,
+			want:     defaults.Scanner.Preprocessing,
+			variants: defaults.Scanner.Variants,
+			sources:  defaults.ItemSources,
+		},
+		{
+			name: "partial stages keep only their own fields",
+			file: `{"scanner": {
+				"preprocessing": [{"stage": "channel"}, {"stage": "sharpen"}, {"stage": "denoise"}],
+				"variants": [{"name": "plain", "preprocessing": [{"stage": "binarize"}]}]
+			}}`,
+			want:     []StageSettings{{Stage: "channel"}, {Stage: "sharpen"}, {Stage: "denoise"}},
+			variants: []VariantSettings{{Name: "plain", Preprocessing: []StageSettings{{Stage: "binarize"}}}},
+			sources:  defaults.ItemSources,
+		},
+		{
+			name:     "sources replace the defaults",
+			file:     `{"itemSources": [{"type": "file"}]}`,
+			want:     defaults.Scanner.Preprocessing,
+			variants: defaults.Scanner.Variants,
+			sources:  []SourceSettings{{Type: SourceFile}},
+		},
+		{
+			name:     "empty lists stay empty",
+			file:     `{"scanner": {"preprocessing": [], "variants": []}}`,
+			want:     []StageSettings{},
+			variants: []VariantSettings{},
+			sources:  defaults.ItemSources,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			settings, err := LoadSettings(path)
+			if err != nil {
+				t.Fatalf("LoadSettings failed: %v", err)
+			}
+			if !reflect.DeepEqual(settings.Scanner.Preprocessing, tt.want) {
+				t.Errorf("preprocessing = %+v, want %+v", settings.Scanner.Preprocessing, tt.want)
+			}
+			if !reflect.DeepEqual(settings.Scanner.Variants, tt.variants) {
+				t.Errorf("variants = %+v, want %+v", settings.Scanner.Variants, tt.variants)
+			}
+			if !reflect.DeepEqual(settings.ItemSources, tt.sources) {
+				t.Errorf("item sources = %+v, want %+v", settings.ItemSources, tt.sources)
+			}
+		})
+	}
+}
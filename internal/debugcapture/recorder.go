@@ -0,0 +1,225 @@
+package debugcapture
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Files written to every scan folder.
+const (
+	RawFile       = "raw.png"
+	ProcessedFile = "processed.png"
+	SlotFile      = "slot.png"
+	TextFile      = "ocr.txt"
+	ScanFile      = "scan.json"
+)
+
+// Decisions recorded in Scan.Decision.
+const (
+	DecisionFound     = "found"
+	DecisionNotFound  = "not-found"
+	DecisionHidden    = "hidden"
+	DecisionError     = "error"
+	DecisionTimeout   = "timeout"   // ran past the scan deadline
+	DecisionCancelled = "cancelled" // replaced by a newer scan
+)
+
+// folderLayout sorts scan folders chronologically by name. Times are in
+// UTC, so the order holds across daylight saving changes.
+const folderLayout = "20060102-150405.000"
+
+// Scan is everything recorded about one scan.
+type Scan struct {
+	Time   time.Time   `json:"time"`
+	Cursor image.Point `json:"cursor"`
+
+	Raw       image.Image `json:"-"`
+	Processed image.Image `json:"-"`
+	Slot      image.Image `json:"-"`
+
+	// Tooltip is the detected tooltip in Raw, empty when none was found
+	Tooltip image.Rectangle `json:"tooltip"`
+
+	Text       string   `json:"text"`
+	Tokens     []string `json:"tokens"`
+	Variant    string   `json:"variant,omitempty"`
+	Confidence float64  `json:"confidence"`
+	Cached     bool     `json:"cached,omitempty"` // read reused from an earlier scan
+
+	Decision string `json:"decision"`
+	ItemID   string `json:"itemId,omitempty"`
+	Source   string `json:"source,omitempty"` // what identified the item: ocr, icon or both
+	Quantity int    `json:"quantity,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Timings in milliseconds, keyed by stage
+	Timings map[string]float64 `json:"timingsMs"`
+}
+
+// Recorder saves scans into one folder each under dir and deletes the
+// oldest folders once their total size exceeds maxBytes.
+type Recorder struct {
+	dir      string
+	maxBytes int64
+
+	mu sync.Mutex
+}
+
+func NewRecorder(dir string, maxBytes int64) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create debug directory: %w", err)
+	}
+	return &Recorder{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Dir returns the directory scans are saved under.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Save writes a scan's images, OCR text and metadata to a new folder and
+// returns its path.
+func (r *Recorder) Save(scan Scan) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	folder, err := r.newFolder(scan.Time)
+	if err != nil {
+		return "", err
+	}
+
+	if scan.Raw != nil {
+		if err := writePNG(filepath.Join(folder, RawFile), scan.Raw); err != nil {
+			return folder, err
+		}
+	}
+	if scan.Processed != nil {
+		if err := writePNG(filepath.Join(folder, ProcessedFile), scan.Processed); err != nil {
+			return folder, err
+		}
+	}
+	if scan.Slot != nil {
+		if err := writePNG(filepath.Join(folder, SlotFile), scan.Slot); err != nil {
+			return folder, err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(folder, TextFile), []byte(scan.Text), 0o644); err != nil {
+		return folder, fmt.Errorf("failed to write OCR text: %w", err)
+	}
+
+	data, err := json.MarshalIndent(scan, "", "  ")
+	if err != nil {
+		return folder, fmt.Errorf("failed to encode scan: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, ScanFile), data, 0o644); err != nil {
+		return folder, fmt.Errorf("failed to write scan: %w", err)
+	}
+
+	if _, err := r.prune(); err != nil {
+		return folder, err
+	}
+	return folder, nil
+}
+
+// newFolder creates a folder named after t, adding a zero-padded suffix
+// that keeps the name order when several scans land in the same
+// millisecond.
+func (r *Recorder) newFolder(t time.Time) (string, error) {
+	base := filepath.Join(r.dir, t.UTC().Format(folderLayout))
+	folder := base
+	for i := 1; ; i++ {
+		err := os.Mkdir(folder, 0o755)
+		if err == nil {
+			return folder, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create scan folder: %w", err)
+		}
+		folder = fmt.Sprintf("%s-%03d", base, i)
+	}
+}
+
+// prune deletes the oldest scan folders until the total size fits the
+// cap. The newest folder is always kept. Returns the number deleted.
+func (r *Recorder) prune() (int, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read debug directory: %w", err)
+	}
+
+	type folder struct {
+		name string
+		size int64
+	}
+	var folders []folder
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size, err := dirSize(filepath.Join(r.dir, entry.Name()))
+		if err != nil {
+			return 0, err
+		}
+		folders = append(folders, folder{name: entry.Name(), size: size})
+		total += size
+	}
+
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].name < folders[j].name
+	})
+
+	removed := 0
+	for len(folders) > 1 && total > r.maxBytes {
+		if err := os.RemoveAll(filepath.Join(r.dir, folders[0].name)); err != nil {
+			return removed, fmt.Errorf("failed to remove old scan: %w", err)
+		}
+		total -= folders[0].size
+		folders = folders[1:]
+		removed++
+	}
+	return removed, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package debugcapture
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testScan(t time.Time) Scan {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7) // noisy so PNGs don't compress to nothing
+	}
+	img.Set(0, 0, color.White)
+
+	return Scan{
+		Time:      t,
+		Cursor:    image.Pt(100, 200),
+		Raw:       img,
+		Processed: img,
+		Tooltip:   image.Rect(8, 4, 56, 60),
+		Text:      "ARC ALLOY\n3/10",
+		Tokens:    []string{"ARC", "ALLOY", "3/10"},
+		Decision:  DecisionFound,
+		ItemID:    "arc-alloy",
+		Quantity:  3,
+		Timings:   map[string]float64{"capture": 12.5, "ocr": 80},
+	}
+}
+
+func TestRecorder_Save(t *testing.T) {
+	r, err := NewRecorder(t.TempDir(), 10<<20)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	folder, err := r.Save(testScan(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	for _, name := range []string{RawFile, ProcessedFile, TextFile, ScanFile} {
+		if _, err := os.Stat(filepath.Join(folder, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(folder, ScanFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Scan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("scan.json unreadable: %v", err)
+	}
+	if saved.ItemID != "arc-alloy" || saved.Decision != DecisionFound || saved.Timings["ocr"] != 80 ||
+		saved.Tooltip != image.Rect(8, 4, 56, 60) {
+		t.Errorf("saved scan = %+v", saved)
+	}
+}
+
+func TestRecorder_SameMillisecond(t *testing.T) {
+	r, err := NewRecorder(t.TempDir(), 10<<20)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	// More than nine collisions, so unpadded suffixes would sort -10
+	// before -2
+	now := time.Now()
+	var folders []string
+	for i := 0; i < 12; i++ {
+		folder, err := r.Save(testScan(now))
+		if err != nil {
+			t.Fatal(err)
+		}
+		folders = append(folders, filepath.Base(folder))
+	}
+
+	if !slices.IsSorted(folders) || len(slices.Compact(slices.Clone(folders))) != len(folders) {
+		t.Errorf("folders = %v, want distinct names in save order", folders)
+	}
+}
+
+func TestRecorder_FolderNamesAcrossDST(t *testing.T) {
+	r, err := NewRecorder(t.TempDir(), 10<<20)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	// After a fall-back the later scan shows an earlier local time
+	summer := time.Date(2026, 11, 1, 1, 30, 0, 0, time.FixedZone("EDT", -4*3600))
+	winter := summer.Add(40 * time.Minute).In(time.FixedZone("EST", -5*3600))
+
+	first, err := r.Save(testScan(summer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.Save(testScan(winter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(first) >= filepath.Base(second) {
+		t.Errorf("folder %s of the later scan sorts before %s", filepath.Base(second), filepath.Base(first))
+	}
+}
+
+func TestRecorder_PrunesOldest(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 1)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	var last string
+	for i := 0; i < 3; i++ {
+		if last, err = r.Save(testScan(start.Add(time.Duration(i) * time.Second))); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(last) {
+		t.Errorf("folders after pruning = %v, want only the newest", entries)
+	}
+}
@@ -0,0 +1,127 @@
+package gridscan
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/inventory"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+// lowestSlots is how many of the cheapest stacks a grid scan highlights.
+const lowestSlots = 5
+
+// Hooks connect a grid scan to the screen, the scanner and the items.
+type Hooks struct {
+	// ScanGrid finds the inventory grid under x, y and reads its slots.
+	ScanGrid func(ctx context.Context, x, y int) ([]scanner.GridSlot, error)
+
+	// ScanTooltip scans the tooltip next to the cursor at x, y.
+	ScanTooltip func(ctx context.Context, x, y int) (scanner.ScanResult, error)
+
+	// Identify finds the item in a scan result and returns what
+	// identified it. For a slot without a tooltip only Slot is set.
+	Identify func(result scanner.ScanResult) (items.Item, string, error)
+
+	// MoveCursor moves the mouse to x, y in cursor coordinates.
+	MoveCursor func(x, y int)
+
+	// Deadline bounds reading the grid and each hovered tooltip.
+	Deadline func(ctx context.Context) (context.Context, context.CancelFunc)
+
+	// LocalIcon returns the URL the overlay loads an item icon from.
+	LocalIcon func(url string) string
+}
+
+// Scan identifies every occupied slot of the inventory grid under the
+// cursor at x, y. Slots are identified by their icon; with
+// GridHoverOCR, slots the icon can't identify are hovered and their
+// tooltip is scanned, and the mouse is put back afterwards. The deadline
+// bounds each step rather than the whole scan, whose length depends on
+// the number of slots hovered.
+func Scan(ctx context.Context, x, y int, settings config.ScannerSettings, hooks Hooks) (inventory.Summary, error) {
+	gridCtx, cancel := hooks.Deadline(ctx)
+	cells, err := hooks.ScanGrid(gridCtx, x, y)
+	cancel()
+	if err != nil {
+		return inventory.Summary{}, err
+	}
+
+	var slots []inventory.Slot
+	unidentified := 0
+	hovered := false
+	for _, cell := range cells {
+		slot, ok := identifySlot(cell, hooks)
+		if !ok && settings.GridHoverOCR && ctx.Err() == nil {
+			slot, ok = hoverSlot(ctx, cell, settings, hooks)
+			hovered = true
+		}
+		if !ok {
+			unidentified++
+			continue
+		}
+		slots = append(slots, slot)
+	}
+
+	// Give the mouse back where the user left it
+	if hovered {
+		hooks.MoveCursor(x, y)
+	}
+	if err := ctx.Err(); err != nil {
+		return inventory.Summary{}, fmt.Errorf("grid scan stopped: %w", err)
+	}
+
+	return inventory.Summarize(slots, unidentified, lowestSlots), nil
+}
+
+// identifySlot identifies a grid slot by its icon alone.
+func identifySlot(cell scanner.GridSlot, hooks Hooks) (inventory.Slot, bool) {
+	item, source, err := hooks.Identify(scanner.ScanResult{Slot: cell.Image})
+	if err != nil || item.Hidden {
+		return inventory.Slot{}, false
+	}
+	return newSlot(cell, item, max(cell.Quantity, 1), source, hooks), true
+}
+
+// hoverSlot moves the mouse onto a slot and identifies it from its
+// tooltip like a regular scan.
+func hoverSlot(ctx context.Context, cell scanner.GridSlot, settings config.ScannerSettings, hooks Hooks) (inventory.Slot, bool) {
+	hooks.MoveCursor(cell.Cursor.X, cell.Cursor.Y)
+	time.Sleep(time.Duration(settings.GridHoverDelayMs) * time.Millisecond)
+
+	ctx, cancel := hooks.Deadline(ctx)
+	defer cancel()
+
+	result, err := hooks.ScanTooltip(ctx, cell.Cursor.X, cell.Cursor.Y)
+	if err != nil {
+		slog.Debug("slot tooltip scan failed", "row", cell.Row, "col", cell.Col, "error", err)
+		return inventory.Slot{}, false
+	}
+	item, source, err := hooks.Identify(result)
+	if err != nil || item.Hidden {
+		return inventory.Slot{}, false
+	}
+
+	quantity := cell.Quantity
+	if quantity == 0 {
+		quantity = items.ParseQuantityWords(result.OCRResult)
+	}
+	return newSlot(cell, item, quantity, source, hooks), true
+}
+
+func newSlot(cell scanner.GridSlot, item items.Item, quantity int, source string, hooks Hooks) inventory.Slot {
+	return inventory.Slot{
+		Row:      cell.Row,
+		Col:      cell.Col,
+		ItemID:   item.ID,
+		Name:     item.Name,
+		Icon:     hooks.LocalIcon(item.Icon),
+		Quantity: quantity,
+		Value:    item.Value,
+		Source:   source,
+	}
+}
@@ -0,0 +1,139 @@
+package gridscan
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+// fakeScreen is a grid whose slots are known by icon, by tooltip or not
+// at all.
+type fakeScreen struct {
+	cells    []scanner.GridSlot
+	byIcon   map[int]items.Item // by cell index
+	byText   map[string]items.Item
+	tooltips map[image.Point]string // tooltip text under a cursor position
+	moves    []image.Point
+}
+
+func (s *fakeScreen) hooks() Hooks {
+	return Hooks{
+		ScanGrid: func(ctx context.Context, x, y int) ([]scanner.GridSlot, error) {
+			if s.cells == nil {
+				return nil, scanner.ErrNoGrid
+			}
+			return s.cells, nil
+		},
+		ScanTooltip: func(ctx context.Context, x, y int) (scanner.ScanResult, error) {
+			return scanner.ScanResult{OCRResult: scanner.OCRResult{Text: s.tooltips[image.Pt(x, y)]}}, nil
+		},
+		Identify: func(result scanner.ScanResult) (items.Item, string, error) {
+			if result.Slot != nil {
+				for i, cell := range s.cells {
+					if cell.Image == result.Slot {
+						if item, ok := s.byIcon[i]; ok {
+							return item, "icon", nil
+						}
+					}
+				}
+				return items.Item{}, "", errors.New("no icon match")
+			}
+			if item, ok := s.byText[result.Text]; ok {
+				return item, "ocr", nil
+			}
+			return items.Item{}, "", errors.New("no text match")
+		},
+		MoveCursor: func(x, y int) { s.moves = append(s.moves, image.Pt(x, y)) },
+		Deadline:   func(ctx context.Context) (context.Context, context.CancelFunc) { return context.WithCancel(ctx) },
+		LocalIcon:  func(url string) string { return "/icons/" + url },
+	}
+}
+
+func newFakeScreen() *fakeScreen {
+	cell := func(row, col int) scanner.GridSlot {
+		return scanner.GridSlot{
+			Row:    row,
+			Col:    col,
+			Image:  image.NewGray(image.Rect(0, 0, 8+row, 8+col)), // distinct pointers
+			Cursor: image.Pt(100+col*10, 100+row*10),
+		}
+	}
+	s := &fakeScreen{
+		cells:  []scanner.GridSlot{cell(0, 0), cell(0, 1), cell(1, 0)},
+		byText: map[string]items.Item{"WIRES": {ID: "wires", Name: "Wires", Value: 20}},
+		byIcon: map[int]items.Item{
+			0: {ID: "arc-alloy", Name: "ARC Alloy", Value: 100, Icon: "alloy.png"},
+			2: {ID: "old", Name: "Old", Value: 5, Hidden: true},
+		},
+		tooltips: map[image.Point]string{image.Pt(110, 100): "WIRES"},
+	}
+	s.cells[0].Quantity = 3
+	return s
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name         string
+		hover        bool
+		wantSlots    int
+		unidentified int
+		total        int
+		moves        int
+	}{
+		{name: "icons only", wantSlots: 1, unidentified: 2, total: 300},
+		{name: "hovering unknown slots", hover: true, wantSlots: 2, unidentified: 1, total: 320, moves: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeScreen()
+			settings := config.ScannerSettings{GridHoverOCR: tt.hover}
+
+			summary, err := Scan(context.Background(), 50, 60, settings, s.hooks())
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if len(summary.Slots) != tt.wantSlots || summary.Unidentified != tt.unidentified || summary.TotalValue != tt.total {
+				t.Errorf("summary = %d slots, %d unidentified, total %d; want %d, %d, %d",
+					len(summary.Slots), summary.Unidentified, summary.TotalValue, tt.wantSlots, tt.unidentified, tt.total)
+			}
+			if summary.Slots[0].Icon != "/icons/alloy.png" {
+				t.Errorf("icon = %q, want the local URL", summary.Slots[0].Icon)
+			}
+
+			if len(s.moves) != tt.moves {
+				t.Errorf("cursor moved %d times, want %d", len(s.moves), tt.moves)
+			}
+			if tt.moves > 0 && s.moves[len(s.moves)-1] != image.Pt(50, 60) {
+				t.Errorf("cursor left at %v, want it back at (50,60)", s.moves[len(s.moves)-1])
+			}
+		})
+	}
+}
+
+func TestScan_NoGrid(t *testing.T) {
+	s := newFakeScreen()
+	s.cells = nil
+
+	if _, err := Scan(context.Background(), 0, 0, config.ScannerSettings{}, s.hooks()); !errors.Is(err, scanner.ErrNoGrid) {
+		t.Errorf("Scan error = %v, want ErrNoGrid", err)
+	}
+}
+
+func TestScan_Cancelled(t *testing.T) {
+	s := newFakeScreen()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Scan(ctx, 0, 0, config.ScannerSettings{GridHoverOCR: true}, s.hooks()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan error = %v, want context.Canceled", err)
+	}
+	if len(s.moves) != 0 {
+		t.Errorf("cancelled scan moved the cursor %d times", len(s.moves))
+	}
+}
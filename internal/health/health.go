@@ -0,0 +1,295 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"arc-scanner/internal/config"
+)
+
+// Names of the checks in a Report.
+const (
+	CheckTesseract = "tesseract"
+	CheckVersion   = "version"
+	CheckLanguage  = "language"
+	CheckSample    = "sample"
+)
+
+// SampleItem is the item shown in the embedded sample tooltip.
+const SampleItem = "ARC Alloy"
+
+// minMajorVersion is the oldest tesseract with the LSTM engine (OEM 1).
+const minMajorVersion = 4
+
+// ErrNoItems is returned by Target.Read when no item data is loaded.
+var ErrNoItems = errors.New("item data not loaded")
+
+// dataFix is the fix for missing item data.
+const dataFix = "The item data is missing or incomplete. Check the internet connection " +
+	"and the itemSources in settings.json, then restart Arc Scanner."
+
+// commandTimeout bounds each tesseract invocation of the self-test.
+const commandTimeout = 10 * time.Second
+
+//go:embed sample_tooltip.png
+var sampleTooltip []byte
+
+// SampleTooltip decodes the embedded tooltip of SampleItem, cropped like
+// a capture after tooltip detection.
+func SampleTooltip() (image.Image, error) {
+	img, err := png.Decode(bytes.NewReader(sampleTooltip))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sample tooltip: %w", err)
+	}
+	return img, nil
+}
+
+// Check is the outcome of one self-test step. Fix tells the user what to
+// do when it failed.
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// Report is the outcome of a self-test.
+type Report struct {
+	OK      bool      `json:"ok"`
+	Time    time.Time `json:"time"`
+	Engine  string    `json:"engine"`
+	Version string    `json:"version,omitempty"`
+	Checks  []Check   `json:"checks"`
+}
+
+// Failed returns the checks that failed.
+func (r Report) Failed() []Check {
+	var failed []Check
+	for _, c := range r.Checks {
+		if !c.OK {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Target is the OCR setup a self-test checks.
+type Target struct {
+	Engine        string // config.OCREngineLib or config.OCREngineExec
+	TesseractPath string
+	TessdataPath  string // empty for tesseract's default location
+	Language      string
+
+	// Read runs a tooltip image through the same preprocessing, OCR and
+	// matching as a scan. It returns the OCR text and the name of the
+	// matched item, or "" when nothing matched, and ErrNoItems when
+	// there is nothing to match against.
+	Read func(ctx context.Context, img image.Image) (text, item string, err error)
+}
+
+// Run checks that tesseract is installed, recent enough and has the
+// language data, then reads the sample tooltip end to end. With the
+// libtesseract engine only the sample is read, the CLI isn't used.
+func Run(ctx context.Context, target Target) Report {
+	report := Report{
+		Time:   time.Now(),
+		Engine: target.Engine,
+	}
+
+	if target.Engine != config.OCREngineLib {
+		binary := checkTesseract(target.TesseractPath)
+		report.Checks = append(report.Checks, binary)
+		if binary.OK {
+			version, check := checkVersion(ctx, target.TesseractPath)
+			report.Version = version
+			report.Checks = append(report.Checks, check, checkLanguage(ctx, target))
+		}
+	}
+	report.Checks = append(report.Checks, checkSample(ctx, target))
+
+	report.OK = len(report.Failed()) == 0
+	return report
+}
+
+func checkTesseract(path string) Check {
+	check := Check{Name: CheckTesseract}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		check.Detail = fmt.Sprintf("tesseract not found (looked for %q)", path)
+		check.Fix = InstallFix()
+		return check
+	}
+	check.OK = true
+	check.Detail = resolved
+	return check
+}
+
+// versionPattern matches the first line of `tesseract --version`, e.g.
+// "tesseract 5.3.0" or "tesseract v5.3.0.20221214".
+var versionPattern = regexp.MustCompile(`tesseract v?(\d+)\.(\d+)(\.\d+)?`)
+
+func checkVersion(ctx context.Context, path string) (string, Check) {
+	check := Check{Name: CheckVersion}
+	output, err := run(ctx, path, "", "--version")
+	if err != nil {
+		check.Detail = fmt.Sprintf("tesseract --version failed: %v", err)
+		check.Fix = InstallFix()
+		return "", check
+	}
+
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		check.Detail = fmt.Sprintf("unrecognized version output %q", firstLine(output))
+		check.Fix = InstallFix()
+		return "", check
+	}
+	version := m[1] + "." + m[2] + m[3]
+	major, _ := strconv.Atoi(m[1])
+	if major < minMajorVersion {
+		check.Detail = fmt.Sprintf("tesseract %s is too old, version %d or newer is needed", version, minMajorVersion)
+		check.Fix = InstallFix()
+		return version, check
+	}
+
+	check.OK = true
+	check.Detail = "tesseract " + version
+	return version, check
+}
+
+// tessdataPattern extracts the directory from the header line of
+// `tesseract --list-langs`.
+var tessdataPattern = regexp.MustCompile(`"([^"]+)"`)
+
+func checkLanguage(ctx context.Context, target Target) Check {
+	check := Check{Name: CheckLanguage}
+	output, err := run(ctx, target.TesseractPath, target.TessdataPath, "--list-langs")
+	dir := target.TessdataPath
+	if m := tessdataPattern.FindStringSubmatch(output); m != nil {
+		dir = m[1]
+	}
+	if err != nil {
+		check.Detail = fmt.Sprintf("tesseract --list-langs failed: %v", err)
+		check.Fix = LanguageFix(target.Language, dir)
+		return check
+	}
+
+	for _, line := range strings.Split(output, "\n")[1:] {
+		if strings.TrimSpace(line) == target.Language {
+			check.OK = true
+			check.Detail = fmt.Sprintf("%s.traineddata found in %s", target.Language, dir)
+			return check
+		}
+	}
+	check.Detail = fmt.Sprintf("%s.traineddata is missing from %s", target.Language, dir)
+	check.Fix = LanguageFix(target.Language, dir)
+	return check
+}
+
+func checkSample(ctx context.Context, target Target) Check {
+	check := Check{Name: CheckSample}
+	img, err := SampleTooltip()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	text, item, err := target.Read(ctx, img)
+	switch {
+	case errors.Is(err, ErrNoItems):
+		check.Detail = "the item data isn't loaded, so the sample can't be matched"
+		check.Fix = dataFix
+	case err != nil:
+		check.Detail = fmt.Sprintf("reading the sample tooltip failed: %v", err)
+		check.Fix = InstallFix()
+	case strings.EqualFold(item, SampleItem):
+		check.OK = true
+		check.Detail = "sample tooltip read as " + item
+	case strings.Contains(strings.ToUpper(text), strings.ToUpper(SampleItem)):
+		check.Detail = fmt.Sprintf("read %q but the item data has no match", SampleItem)
+		check.Fix = dataFix
+	default:
+		check.Detail = fmt.Sprintf("expected %s, read %q", SampleItem, firstLine(strings.TrimSpace(text)))
+		check.Fix = "The preprocessing settings garble text. Remove scanner.preprocessing " +
+			"from settings.json to restore the defaults, then restart Arc Scanner."
+	}
+	return check
+}
+
+// run runs tesseract and returns its combined output. Older versions
+// print --list-langs and --version to stderr.
+func run(ctx context.Context, path, tessdataPath string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	if tessdataPath != "" {
+		cmd.Env = append(os.Environ(), "TESSDATA_PREFIX="+tessdataPath)
+	}
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// InstallFix tells the user how to get a working tesseract on this
+// platform.
+func InstallFix() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Reinstall Arc Scanner, which bundles Tesseract, or install Tesseract " +
+			`from https://github.com/UB-Mannheim/tesseract/wiki into C:\Program Files\Tesseract-OCR.`
+	case "darwin":
+		return "Reinstall Arc Scanner, which bundles Tesseract, or install it with: brew install tesseract"
+	default:
+		return "Install Tesseract 4 or newer with your package manager, e.g.: sudo apt install tesseract-ocr"
+	}
+}
+
+// CaptureFix tells the user how to let Arc Scanner capture the screen on
+// this platform.
+func CaptureFix() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Run the game in borderless windowed mode. Exclusive fullscreen and " +
+			"protected windows can't be captured."
+	case "darwin":
+		return "Allow Arc Scanner under System Settings > Privacy & Security > " +
+			"Screen Recording, then restart it."
+	default:
+		return "Run the game in an X11 session (Wayland screens can't be captured) " +
+			"in borderless windowed mode."
+	}
+}
+
+// LanguageFix tells the user how to install the language data for lang,
+// which tesseract looks for in dir.
+func LanguageFix(lang, dir string) string {
+	download := fmt.Sprintf("download %s.traineddata from https://github.com/tesseract-ocr/tessdata_fast", lang)
+	if dir != "" {
+		download += " into " + dir
+	}
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return "Reinstall Arc Scanner, which bundles the language data, or " + download + "."
+	default:
+		return fmt.Sprintf("Install the language data, e.g.: sudo apt install tesseract-ocr-%s, or %s.", lang, download)
+	}
+}
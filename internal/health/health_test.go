@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/scanner"
+)
+
+// fakeTesseract writes a script answering --version and --list-langs.
+func fakeTesseract(t *testing.T, version string, langs ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as tesseract")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"case \"$1\" in\n" +
+		"--version) echo 'tesseract " + version + "'; echo ' leptonica-1.82.0' ;;\n" +
+		"--list-langs) echo 'List of available languages in \"" + dir + "/\" (" + string(rune('0'+len(langs))) + "):'\n" +
+		"  printf '%s\\n' " + strings.Join(langs, " ") + " ;;\n" +
+		"esac\n"
+	path := filepath.Join(dir, "tesseract")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func reader(text, item string, err error) func(context.Context, image.Image) (string, string, error) {
+	return func(context.Context, image.Image) (string, string, error) {
+		return text, item, err
+	}
+}
+
+func TestRun(t *testing.T) {
+	good := reader("ARC ALLOY\nTopside Material", SampleItem, nil)
+
+	tests := []struct {
+		name    string
+		target  func(t *testing.T) Target
+		failed  []string
+		version string
+	}{
+		{
+			name: "healthy",
+			target: func(t *testing.T) Target {
+				return Target{TesseractPath: fakeTesseract(t, "5.3.0", "eng", "osd"), Read: good}
+			},
+			version: "5.3.0",
+		},
+		{
+			name: "missing binary",
+			target: func(t *testing.T) Target {
+				return Target{
+					TesseractPath: filepath.Join(t.TempDir(), "tesseract"),
+					Read:          reader("", "", errors.New("executable file not found")),
+				}
+			},
+			failed: []string{CheckTesseract, CheckSample},
+		},
+		{
+			name: "too old",
+			target: func(t *testing.T) Target {
+				return Target{TesseractPath: fakeTesseract(t, "3.05.02", "eng"), Read: good}
+			},
+			failed:  []string{CheckVersion},
+			version: "3.05.02",
+		},
+		{
+			name: "missing language",
+			target: func(t *testing.T) Target {
+				return Target{TesseractPath: fakeTesseract(t, "v5.0.0.20211201", "osd"), Read: good}
+			},
+			failed:  []string{CheckLanguage},
+			version: "5.0.0",
+		},
+		{
+			name: "item data without the sample",
+			target: func(t *testing.T) Target {
+				return Target{TesseractPath: fakeTesseract(t, "5.3.0", "eng"), Read: reader("ARC ALLOY", "", nil)}
+			},
+			failed:  []string{CheckSample},
+			version: "5.3.0",
+		},
+		{
+			name: "items not loaded",
+			target: func(t *testing.T) Target {
+				return Target{TesseractPath: fakeTesseract(t, "5.3.0", "eng"), Read: reader("", "", ErrNoItems)}
+			},
+			failed:  []string{CheckSample},
+			version: "5.3.0",
+		},
+		{
+			name: "libtesseract skips the CLI",
+			target: func(t *testing.T) Target {
+				return Target{Engine: config.OCREngineLib, TesseractPath: "missing-tesseract", Read: good}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target(t)
+			target.Language = "eng"
+			report := Run(context.Background(), target)
+
+			var failed []string
+			for _, c := range report.Failed() {
+				failed = append(failed, c.Name)
+				if c.Fix == "" {
+					t.Errorf("%s failed without a fix: %s", c.Name, c.Detail)
+				}
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.failed, ",") {
+				t.Errorf("failed = %v, want %v", failed, tt.failed)
+			}
+			if report.OK != (len(tt.failed) == 0) {
+				t.Errorf("OK = %v with failures %v", report.OK, failed)
+			}
+			if report.Version != tt.version {
+				t.Errorf("Version = %q, want %q", report.Version, tt.version)
+			}
+		})
+	}
+}
+
+func TestSampleTooltip_Readable(t *testing.T) {
+	path, err := exec.LookPath("tesseract")
+	if err != nil {
+		t.Skip("tesseract not installed")
+	}
+	img, err := SampleTooltip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline, err := scanner.NewPipeline(config.DefaultSettings().Scanner.Preprocessing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processed, err := pipeline.Apply(context.Background(), img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := scanner.NewExecEngine(path, "").Recognize(context.Background(), processed)
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if !strings.Contains(result.Text, strings.ToUpper(SampleItem)) {
+		t.Errorf("sample read as %q, want %s", result.Text, strings.ToUpper(SampleItem))
+	}
+}
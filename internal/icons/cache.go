@@ -0,0 +1,420 @@
+package icons
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "golang.org/x/image/webp"
+)
+
+// URLPrefix is the asset server path local icons are served under.
+const URLPrefix = "/icons/"
+
+const (
+	indexFile       = "index.json"
+	printsFile      = "fingerprints.json"
+	downloadWorkers = 4
+	maxIconSize     = 2 << 20
+)
+
+// Cache keeps item icons on disk under content-addressed names
+// (sha256 of the image bytes) and maps remote icon URLs to them.
+type Cache struct {
+	dir        string
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	files  map[string]string      // remote URL -> file name
+	prints map[string]Fingerprint // file name -> icon fingerprint
+}
+
+func NewCache(dir string) *Cache {
+	c := &Cache{
+		dir: dir,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		files:  make(map[string]string),
+		prints: make(map[string]Fingerprint),
+	}
+
+	if err := c.loadIndex(); err != nil && !os.IsNotExist(err) {
+		slog.Warn("icon index unreadable, starting empty", "error", err)
+	}
+	if err := c.loadPrints(); err != nil && !os.IsNotExist(err) {
+		slog.Warn("icon fingerprints unreadable, rehashing", "error", err)
+	}
+
+	return c
+}
+
+// Sync downloads every icon in urls that isn't cached yet. It blocks
+// until done, so callers usually run it in a goroutine.
+func (c *Cache) Sync(ctx context.Context, urls []string) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create icon directory: %w", err)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var downloaded, failed int
+	var countMu sync.Mutex
+
+	for i := 0; i < downloadWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				err := c.download(ctx, url)
+
+				countMu.Lock()
+				if err != nil {
+					failed++
+					slog.Debug("icon download failed", "url", url, "error", err)
+				} else {
+					downloaded++
+				}
+				countMu.Unlock()
+			}
+		}()
+	}
+
+queue:
+	for _, url := range urls {
+		if _, ok := c.LocalURL(url); ok || url == "" {
+			continue
+		}
+		select {
+		case jobs <- url:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if downloaded > 0 {
+		if err := c.saveIndex(); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("icon cache synced", "downloaded", downloaded, "failed", failed)
+	return ctx.Err()
+}
+
+// Prune forgets URLs not in keep and deletes files no longer referenced.
+func (c *Cache) Prune(keep []string) (int, error) {
+	wanted := make(map[string]bool, len(keep))
+	for _, url := range keep {
+		wanted[url] = true
+	}
+
+	c.mu.Lock()
+	referenced := make(map[string]bool, len(c.files))
+	for url, name := range c.files {
+		if !wanted[url] {
+			delete(c.files, url)
+			continue
+		}
+		referenced[name] = true
+	}
+	for name := range c.prints {
+		if !referenced[name] {
+			delete(c.prints, name)
+		}
+	}
+	c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read icon directory: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == indexFile || name == printsFile || referenced[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil {
+			slog.Warn("failed to remove icon", "file", name, "error", err)
+			continue
+		}
+		removed++
+	}
+
+	if err := c.saveIndex(); err != nil {
+		return removed, err
+	}
+	if err := c.savePrints(); err != nil {
+		return removed, err
+	}
+
+	slog.Info("icon cache pruned", "removed", removed)
+	return removed, nil
+}
+
+// LocalURL returns the asset server URL for a cached remote icon.
+func (c *Cache) LocalURL(remote string) (string, bool) {
+	c.mu.RLock()
+	name, ok := c.files[remote]
+	c.mu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+	return URLPrefix + name, true
+}
+
+// Path returns the on-disk path of a cached remote icon.
+func (c *Cache) Path(remote string) (string, bool) {
+	c.mu.RLock()
+	name, ok := c.files[remote]
+	c.mu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(c.dir, name), true
+}
+
+// ServeHTTP serves cached icons under URLPrefix.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, URLPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Names are hex digests, so anything with a separator is bogus
+	name := strings.TrimPrefix(r.URL.Path, URLPrefix)
+	if name == "" || name == indexFile || name == printsFile || strings.ContainsAny(name, `/\`) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Content-addressed files never change
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeFile(w, r, filepath.Join(c.dir, name))
+}
+
+// BuildIndex fingerprints the cached icons of keys (e.g. item ID -> icon
+// URL) and indexes them by key. Fingerprints are kept on disk, so only
+// new icons are decoded. Icons that aren't cached or can't be decoded
+// are left out.
+func (c *Cache) BuildIndex(keys map[string]string) (*Index, error) {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	index := NewIndex()
+	hashed := 0
+	for _, key := range sorted {
+		c.mu.RLock()
+		name, ok := c.files[keys[key]]
+		fp, known := c.prints[name]
+		c.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		if !known {
+			var err error
+			if fp, err = c.fingerprint(name); err != nil {
+				slog.Debug("icon not indexed", "key", key, "error", err)
+				continue
+			}
+			c.mu.Lock()
+			c.prints[name] = fp
+			c.mu.Unlock()
+			hashed++
+		}
+		index.Add(key, fp)
+	}
+
+	if hashed > 0 {
+		if err := c.savePrints(); err != nil {
+			return index, err
+		}
+	}
+
+	slog.Info("icon index built", "icons", index.Len(), "hashed", hashed)
+	return index, nil
+}
+
+func (c *Cache) fingerprint(name string) (Fingerprint, error) {
+	f, err := os.Open(filepath.Join(c.dir, name))
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to open icon: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to decode icon: %w", err)
+	}
+	return IconFingerprint(img), nil
+}
+
+func (c *Cache) download(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download icon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// One byte past the limit tells a full-size icon from a cut-off one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIconSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read icon: %w", err)
+	}
+	if len(data) > maxIconSize {
+		return fmt.Errorf("icon larger than %d bytes", maxIconSize)
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:]) + iconExt(url)
+	filePath := filepath.Join(c.dir, name)
+
+	if _, err := os.Stat(filePath); err != nil {
+		if err := writeFileAtomic(filePath, data); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.files[url] = name
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cache) loadIndex() error {
+	data, err := os.ReadFile(filepath.Join(c.dir, indexFile))
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]string)
+	if err := json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("failed to parse icon index: %w", err)
+	}
+
+	// Drop entries whose file has gone missing
+	for url, name := range files {
+		if _, err := os.Stat(filepath.Join(c.dir, name)); err != nil {
+			delete(files, url)
+		}
+	}
+
+	c.mu.Lock()
+	c.files = files
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cache) saveIndex() error {
+	c.mu.RLock()
+	data, err := json.Marshal(c.files)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode icon index: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, indexFile), data); err != nil {
+		return fmt.Errorf("failed to write icon index: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) loadPrints() error {
+	data, err := os.ReadFile(filepath.Join(c.dir, printsFile))
+	if err != nil {
+		return err
+	}
+
+	prints := make(map[string]Fingerprint)
+	if err := json.Unmarshal(data, &prints); err != nil {
+		return fmt.Errorf("failed to parse icon fingerprints: %w", err)
+	}
+
+	c.mu.Lock()
+	c.prints = prints
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cache) savePrints() error {
+	c.mu.RLock()
+	data, err := json.Marshal(c.prints)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode icon fingerprints: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, printsFile), data); err != nil {
+		return fmt.Errorf("failed to write icon fingerprints: %w", err)
+	}
+	return nil
+}
+
+func iconExt(url string) string {
+	if i := strings.IndexAny(url, "?#"); i != -1 {
+		url = url[:i]
+	}
+	switch ext := strings.ToLower(path.Ext(url)); ext {
+	case ".png", ".jpg", ".jpeg", ".webp", ".gif", ".svg":
+		return ext
+	default:
+		return ".png"
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,148 @@
+package icons
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newIconServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "missing.png") {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "huge.png") {
+			w.Write(make([]byte, maxIconSize+1))
+			return
+		}
+		// Same bytes for both "same" icons to exercise content addressing
+		body := r.URL.Path
+		if strings.HasPrefix(r.URL.Path, "/same") {
+			body = "same"
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCache_SyncAndServe(t *testing.T) {
+	server := newIconServer(t)
+	dir := t.TempDir()
+	cache := NewCache(dir)
+
+	urls := []string{
+		server.URL + "/a.png",
+		server.URL + "/same-1.png",
+		server.URL + "/same-2.png",
+		server.URL + "/missing.png",
+	}
+
+	if err := cache.Sync(context.Background(), urls); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	local, ok := cache.LocalURL(urls[0])
+	if !ok {
+		t.Fatal("LocalURL: a.png not cached")
+	}
+	if !strings.HasPrefix(local, URLPrefix) {
+		t.Errorf("LocalURL = %s, want prefix %s", local, URLPrefix)
+	}
+
+	same1, _ := cache.LocalURL(urls[1])
+	same2, _ := cache.LocalURL(urls[2])
+	if same1 != same2 {
+		t.Errorf("identical icons got different names: %s, %s", same1, same2)
+	}
+
+	if _, ok := cache.LocalURL(urls[3]); ok {
+		t.Error("LocalURL: failed download should not be cached")
+	}
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, local, nil))
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || string(body) != "/a.png" {
+		t.Errorf("ServeHTTP = %d %q, want 200 %q", rec.Code, body, "/a.png")
+	}
+
+	// A fresh cache picks the mapping back up from the index
+	reloaded := NewCache(dir)
+	if got, ok := reloaded.LocalURL(urls[0]); !ok || got != local {
+		t.Errorf("reloaded LocalURL = %s, %v, want %s", got, ok, local)
+	}
+}
+
+func TestCache_SkipsOversizedIcons(t *testing.T) {
+	server := newIconServer(t)
+	dir := t.TempDir()
+	cache := NewCache(dir)
+
+	huge := server.URL + "/huge.png"
+	if err := cache.download(context.Background(), huge); err == nil {
+		t.Error("download of an oversized icon succeeded")
+	}
+	if _, ok := cache.LocalURL(huge); ok {
+		t.Error("oversized icon was cached")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("oversized icon left %d files behind", len(entries))
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	server := newIconServer(t)
+	dir := t.TempDir()
+	cache := NewCache(dir)
+
+	keep := server.URL + "/keep.png"
+	drop := server.URL + "/drop.png"
+
+	if err := cache.Sync(context.Background(), []string{keep, drop}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	dropPath, _ := cache.Path(drop)
+
+	removed, err := cache.Prune([]string{keep})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d files, want 1", removed)
+	}
+
+	if _, err := os.Stat(dropPath); !os.IsNotExist(err) {
+		t.Error("pruned icon still on disk")
+	}
+	if _, ok := cache.LocalURL(drop); ok {
+		t.Error("pruned icon still has a local URL")
+	}
+	if _, ok := cache.LocalURL(keep); !ok {
+		t.Error("kept icon lost its local URL")
+	}
+	if _, err := os.Stat(filepath.Join(dir, indexFile)); err != nil {
+		t.Errorf("index removed by Prune: %v", err)
+	}
+}
+
+func TestCache_ServeHTTP_RejectsTraversal(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	for _, path := range []string{"/icons/", "/icons/../items.json", "/icons/index.json", "/other/x.png"} {
+		rec := httptest.NewRecorder()
+		cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("ServeHTTP(%s) = %d, want 404", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package icons
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+// FingerprintBits is the largest possible distance between two
+// fingerprints.
+const FingerprintBits = 128
+
+// slotBackground is what transparent icon pixels are flattened onto, so
+// downloaded icons look like they do in an inventory slot.
+var slotBackground = color.NRGBA{24, 24, 24, 255}
+
+// Fingerprint is a pair of perceptual hashes of an image: pHash for its
+// overall structure and dHash for local gradients. Similar images have
+// fingerprints a small Hamming distance apart.
+type Fingerprint struct {
+	PHash uint64 `json:"p"`
+	DHash uint64 `json:"d"`
+}
+
+// NewFingerprint hashes an image as it is.
+func NewFingerprint(img image.Image) Fingerprint {
+	gray := imaging.Grayscale(img)
+	return Fingerprint{
+		PHash: pHash(gray),
+		DHash: dHash(gray),
+	}
+}
+
+// IconFingerprint hashes a downloaded icon. Transparent margins are
+// trimmed and the rest is flattened onto the slot background first.
+func IconFingerprint(img image.Image) Fingerprint {
+	if content := opaqueBounds(img); !content.Empty() {
+		img = imaging.Crop(img, content)
+	}
+	flat := imaging.New(img.Bounds().Dx(), img.Bounds().Dy(), slotBackground)
+	return NewFingerprint(imaging.Overlay(flat, img, image.Point{}, 1))
+}
+
+// SlotFingerprint hashes a captured inventory slot. Like icons, it is
+// trimmed to its content first: whatever stands out from the slot
+// background, estimated from the border pixels.
+func SlotFingerprint(img image.Image) Fingerprint {
+	if content := contentBounds(img); !content.Empty() {
+		img = imaging.Crop(img, content)
+	}
+	return NewFingerprint(img)
+}
+
+// Distance is the number of differing bits, from 0 to FingerprintBits.
+func (f Fingerprint) Distance(other Fingerprint) int {
+	return bits.OnesCount64(f.PHash^other.PHash) + bits.OnesCount64(f.DHash^other.DHash)
+}
+
+// pHash keeps the sign of the lowest 8x8 frequencies of a 32x32 DCT
+// relative to their median.
+func pHash(img image.Image) uint64 {
+	const size, keep = 32, 8
+
+	small := imaging.Resize(img, size, size, imaging.Box)
+	pixels := make([][]float64, size)
+	for y := range pixels {
+		pixels[y] = make([]float64, size)
+		for x := range pixels[y] {
+			pixels[y][x] = float64(small.Pix[y*small.Stride+x*4])
+		}
+	}
+
+	freq := dct2D(pixels, keep)
+
+	// The DC term is the mean brightness, not structure
+	coeffs := make([]float64, 0, keep*keep-1)
+	for y := 0; y < keep; y++ {
+		for x := 0; x < keep; x++ {
+			if x != 0 || y != 0 {
+				coeffs = append(coeffs, freq[y][x])
+			}
+		}
+	}
+	sorted := append([]float64(nil), coeffs...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// dHash compares each pixel of a 9x8 thumbnail with its right neighbor.
+func dHash(img image.Image) uint64 {
+	small := imaging.Resize(img, 9, 8, imaging.Box)
+
+	var hash uint64
+	bit := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			left := small.Pix[y*small.Stride+x*4]
+			right := small.Pix[y*small.Stride+(x+1)*4]
+			if left > right {
+				hash |= 1 << bit
+			}
+			bit++
+		}
+	}
+	return hash
+}
+
+// dct2D returns the lowest keep x keep coefficients of the 2D DCT-II of
+// a square matrix.
+func dct2D(pixels [][]float64, keep int) [][]float64 {
+	n := len(pixels)
+
+	cos := make([][]float64, keep)
+	for u := range cos {
+		cos[u] = make([]float64, n)
+		for x := range cos[u] {
+			cos[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / float64(2*n))
+		}
+	}
+
+	// Rows first, then columns
+	rows := make([][]float64, n)
+	for y := range rows {
+		rows[y] = make([]float64, keep)
+		for u := 0; u < keep; u++ {
+			sum := 0.0
+			for x := 0; x < n; x++ {
+				sum += pixels[y][x] * cos[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+
+	out := make([][]float64, keep)
+	for v := range out {
+		out[v] = make([]float64, keep)
+		for u := 0; u < keep; u++ {
+			sum := 0.0
+			for y := 0; y < n; y++ {
+				sum += rows[y][u] * cos[v][y]
+			}
+			out[v][u] = sum
+		}
+	}
+	return out
+}
+
+// opaqueBounds returns the bounds of the pixels that aren't fully
+// transparent.
+func opaqueBounds(img image.Image) image.Rectangle {
+	var content image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0 {
+				content = content.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return content
+}
+
+// slotContentContrast is how far, in 8-bit luminance, a pixel must be
+// from the slot background to count as part of the icon.
+const slotContentContrast = 24
+
+// contentBounds returns the bounds of the pixels that differ from the
+// image's border.
+func contentBounds(img image.Image) image.Rectangle {
+	gray := imaging.Grayscale(img)
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	if w < 3 || h < 3 {
+		return image.Rectangle{}
+	}
+	lum := func(x, y int) int {
+		return int(gray.Pix[y*gray.Stride+x*4])
+	}
+
+	var border []int
+	for x := 0; x < w; x++ {
+		border = append(border, lum(x, 0), lum(x, h-1))
+	}
+	for y := 1; y < h-1; y++ {
+		border = append(border, lum(0, y), lum(w-1, y))
+	}
+	sort.Ints(border)
+	background := border[len(border)/2]
+
+	var content image.Rectangle
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if d := lum(x, y) - background; d > slotContentContrast || d < -slotContentContrast {
+				content = content.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return content
+}
+
+// Neighbor is an index entry close to a queried fingerprint.
+type Neighbor struct {
+	Key      string
+	Distance int
+
+	// Similarity maps Distance to 0-1, reaching 0 at the distance
+	// Nearest was limited to.
+	Similarity float64
+}
+
+// Index finds the entries nearest to a fingerprint. It is a linear scan,
+// which is plenty for a few thousand icons.
+type Index struct {
+	keys   []string
+	prints []Fingerprint
+}
+
+func NewIndex() *Index {
+	return &Index{}
+}
+
+// Add stores a fingerprint under key, e.g. an item ID. Several keys may
+// share a fingerprint, as tiers of an item often share an icon.
+func (x *Index) Add(key string, fp Fingerprint) {
+	x.keys = append(x.keys, key)
+	x.prints = append(x.prints, fp)
+}
+
+// Len returns the number of entries.
+func (x *Index) Len() int {
+	return len(x.keys)
+}
+
+// Nearest returns up to k entries closer than maxDistance, nearest
+// first.
+func (x *Index) Nearest(fp Fingerprint, k, maxDistance int) []Neighbor {
+	var neighbors []Neighbor
+	for i, p := range x.prints {
+		d := p.Distance(fp)
+		if d >= maxDistance {
+			continue
+		}
+		neighbors = append(neighbors, Neighbor{
+			Key:        x.keys[i],
+			Distance:   d,
+			Similarity: 1 - float64(d)/float64(maxDistance),
+		})
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Distance != neighbors[j].Distance {
+			return neighbors[i].Distance < neighbors[j].Distance
+		}
+		return neighbors[i].Key < neighbors[j].Key
+	})
+
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
@@ -0,0 +1,148 @@
+package icons
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+// testIcon draws a transparent icon: a light diagonal bar, or a ring
+// when ring is set.
+func testIcon(ring bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			dx, dy := x-32, y-32
+			var on bool
+			if ring {
+				d := dx*dx + dy*dy
+				on = d > 14*14 && d < 24*24
+			} else {
+				on = x-y > -12 && x-y < 12 && x > 6 && x < 58
+			}
+			if on {
+				img.Set(x, y, color.NRGBA{220, 200 - uint8(y), 90, 255})
+			}
+		}
+	}
+	return img
+}
+
+// inSlot renders an icon the way a captured slot shows it: padded on the
+// slot background, at another size.
+func inSlot(icon image.Image, size int) image.Image {
+	slot := imaging.New(96, 96, slotBackground)
+	slot = imaging.Overlay(slot, icon, image.Pt(16, 16), 1)
+	return imaging.Resize(slot, size, size, imaging.Lanczos)
+}
+
+func TestFingerprint_Distance(t *testing.T) {
+	bar := IconFingerprint(testIcon(false))
+	ring := IconFingerprint(testIcon(true))
+
+	if d := bar.Distance(bar); d != 0 {
+		t.Errorf("distance to itself = %d, want 0", d)
+	}
+
+	captured := SlotFingerprint(inSlot(testIcon(false), 80))
+	near, far := bar.Distance(captured), ring.Distance(captured)
+	if near > 16 {
+		t.Errorf("captured bar is %d from the bar icon, want at most 16", near)
+	}
+	if near >= far {
+		t.Errorf("captured bar is %d from the bar icon and %d from the ring, want it nearer the bar", near, far)
+	}
+}
+
+func TestIndex_Nearest(t *testing.T) {
+	index := NewIndex()
+	index.Add("b", Fingerprint{PHash: 0b1})
+	index.Add("a", Fingerprint{PHash: 0b1})
+	index.Add("c", Fingerprint{PHash: 0b111})
+	index.Add("far", Fingerprint{PHash: ^uint64(0)})
+
+	got := index.Nearest(Fingerprint{}, 3, 10)
+	want := []Neighbor{
+		{Key: "a", Distance: 1, Similarity: 0.9},
+		{Key: "b", Distance: 1, Similarity: 0.9},
+		{Key: "c", Distance: 3, Similarity: 0.7},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Nearest = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Nearest[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := index.Nearest(Fingerprint{}, 1, 10); len(got) != 1 {
+		t.Errorf("Nearest with k=1 returned %d entries", len(got))
+	}
+}
+
+func TestCache_BuildIndex(t *testing.T) {
+	var bar, ring bytes.Buffer
+	png.Encode(&bar, testIcon(false))
+	png.Encode(&ring, testIcon(true))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bar.png":
+			w.Write(bar.Bytes())
+		case "/ring.png":
+			w.Write(ring.Bytes())
+		default:
+			w.Write([]byte("not an image"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	keys := map[string]string{
+		"knife-i":  server.URL + "/bar.png",
+		"knife-ii": server.URL + "/bar.png",
+		"gear":     server.URL + "/ring.png",
+		"broken":   server.URL + "/broken.png",
+		"uncached": server.URL + "/uncached.png",
+	}
+
+	cache := NewCache(dir)
+	urls := []string{keys["knife-i"], keys["gear"], keys["broken"]}
+	if err := cache.Sync(context.Background(), urls); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	index, err := cache.BuildIndex(keys)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	if index.Len() != 3 {
+		t.Errorf("index has %d entries, want knife-i, knife-ii and gear", index.Len())
+	}
+
+	captured := SlotFingerprint(inSlot(testIcon(true), 80))
+	nearest := index.Nearest(captured, 1, FingerprintBits)
+	if len(nearest) != 1 || nearest[0].Key != "gear" {
+		t.Errorf("Nearest(captured ring) = %+v, want gear", nearest)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, printsFile)); err != nil {
+		t.Fatalf("fingerprints not saved: %v", err)
+	}
+
+	// A new cache reuses the saved fingerprints
+	reloaded := NewCache(dir)
+	if len(reloaded.prints) != 2 {
+		t.Errorf("reloaded %d fingerprints, want 2", len(reloaded.prints))
+	}
+}
@@ -0,0 +1,85 @@
+package identify
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"arc-scanner/internal/icons"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+// Icon matching limits: how many nearest icons vote and how far their
+// fingerprints may be from the slot's.
+const (
+	iconNeighbors   = 5
+	iconMaxDistance = 40
+)
+
+// Identifier names the item in a scan from its text and the hovered
+// slot's icon, the same way for live scans, grid scans and replays.
+type Identifier struct {
+	matcher *items.Matcher
+	index   *icons.Index // nil without icon matching
+	items   items.ItemMap
+}
+
+// New identifies items of itemsMap by matcher and, when index is not
+// nil, by their icons.
+func New(matcher *items.Matcher, index *icons.Index, itemsMap items.ItemMap) *Identifier {
+	return &Identifier{
+		matcher: matcher,
+		index:   index,
+		items:   itemsMap,
+	}
+}
+
+// Identify combines the OCR match of a scan with the item icons nearest
+// to the hovered slot. It returns the item and what identified it:
+// "ocr", "icon" or "ocr+icon".
+func (id *Identifier) Identify(result scanner.ScanResult) (items.Item, string, error) {
+	var ocr, icon []items.Candidate
+	if item, score, err := id.matcher.Match(result.OCRResult); err == nil {
+		ocr = append(ocr, items.Candidate{ID: item.ID, Score: score})
+	}
+
+	if id.index != nil && result.Slot != nil {
+		fp := icons.SlotFingerprint(result.Slot)
+		for _, n := range id.index.Nearest(fp, iconNeighbors, iconMaxDistance) {
+			icon = append(icon, items.Candidate{ID: n.Key, Score: n.Similarity})
+		}
+	}
+
+	best, ok := items.Combine(ocr, icon)
+	if !ok {
+		slog.Debug("no confident identification", "ocr", ocr, "icon", icon)
+		return items.Item{}, "", items.ErrItemNotFound
+	}
+	item, ok := id.items.Get(best.ID)
+	if !ok {
+		return items.Item{}, "", items.ErrItemNotFound
+	}
+
+	var sources []string
+	if slices.ContainsFunc(ocr, func(c items.Candidate) bool { return c.ID == best.ID }) {
+		sources = append(sources, "ocr")
+	}
+	if slices.ContainsFunc(icon, func(c items.Candidate) bool { return c.ID == best.ID }) {
+		sources = append(sources, "icon")
+	}
+	return item, strings.Join(sources, "+"), nil
+}
+
+// IconIndex fingerprints the cached icons of itemsList for icon matching.
+// Items whose icon isn't cached are left out. The index is usable even
+// when saving the fingerprints fails.
+func IconIndex(cache *icons.Cache, itemsList []items.Item) (*icons.Index, error) {
+	keys := make(map[string]string, len(itemsList))
+	for _, item := range itemsList {
+		if item.Icon != "" {
+			keys[item.ID] = item.Icon
+		}
+	}
+	return cache.BuildIndex(keys)
+}
@@ -0,0 +1,72 @@
+package identify
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"arc-scanner/internal/icons"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+// slotImage draws a bright cross on a dark slot, so it has an icon to
+// fingerprint.
+func slotImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.NRGBA{20, 20, 20, 255}
+			if (x > 24 && x < 40) || (y > 8 && y < 20) {
+				c = color.NRGBA{230, 200, 90, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestIdentify(t *testing.T) {
+	itemsList := []items.Item{
+		{ID: "arc-alloy", Name: "ARC Alloy"},
+		{ID: "wires", Name: "Wires"},
+	}
+	matcher := items.NewMatcher(itemsList)
+	slot := slotImage()
+
+	index := icons.NewIndex()
+	index.Add("arc-alloy", icons.SlotFingerprint(slot))
+
+	read := func(text string) scanner.OCRResult {
+		return scanner.OCRResult{Text: text, Confidence: 90}
+	}
+
+	tests := []struct {
+		name   string
+		index  *icons.Index
+		result scanner.ScanResult
+		want   string
+		source string
+	}{
+		{name: "text only", result: scanner.ScanResult{OCRResult: read("ARC ALLOY")}, want: "arc-alloy", source: "ocr"},
+		{name: "icon only", index: index, result: scanner.ScanResult{OCRResult: read("???"), Slot: slot}, want: "arc-alloy", source: "icon"},
+		{name: "text and icon", index: index, result: scanner.ScanResult{OCRResult: read("ARC ALLOY"), Slot: slot}, want: "arc-alloy", source: "ocr+icon"},
+		{name: "icons off", result: scanner.ScanResult{OCRResult: read("???"), Slot: slot}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, source, err := New(matcher, tt.index, items.BuildIndex(itemsList)).Identify(tt.result)
+			if tt.want == "" {
+				if !errors.Is(err, items.ErrItemNotFound) {
+					t.Errorf("Identify = %s, %v, want ErrItemNotFound", item.ID, err)
+				}
+				return
+			}
+			if err != nil || item.ID != tt.want || source != tt.source {
+				t.Errorf("Identify = %s (%s), %v, want %s (%s)", item.ID, source, err, tt.want, tt.source)
+			}
+		})
+	}
+}
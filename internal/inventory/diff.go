@@ -0,0 +1,105 @@
+package inventory
+
+import "sort"
+
+// Change is how much of one item there is in two snapshots.
+type Change struct {
+	ItemID string `json:"itemId"`
+	Name   string `json:"name"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+	Value  int    `json:"value"` // Delta times the unit value
+}
+
+// Diff is what changed between two snapshots, e.g. over a raid.
+type Diff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	Gained []Change `json:"gained"` // more of it, including new items
+	Used   []Change `json:"used"`   // less of it, but some is left
+	Lost   []Change `json:"lost"`   // none left
+
+	NetValue int `json:"netValue"`
+}
+
+// Compare diffs two snapshots item by item, adding up stacks spread over
+// several slots. Values come from the snapshot the item is in, preferring
+// the newer one.
+func Compare(before, after Snapshot) Diff {
+	type total struct {
+		name          string
+		before, after int
+		value         int
+	}
+
+	totals := make(map[string]*total)
+	get := func(slot Slot) *total {
+		t, ok := totals[slot.ItemID]
+		if !ok {
+			t = &total{}
+			totals[slot.ItemID] = t
+		}
+		t.name, t.value = slot.Name, slot.Value
+		return t
+	}
+	for _, slot := range before.Slots {
+		get(slot).before += slot.Quantity
+	}
+	for _, slot := range after.Slots {
+		get(slot).after += slot.Quantity
+	}
+
+	diff := Diff{
+		From:     before.ID,
+		To:       after.ID,
+		Gained:   []Change{},
+		Used:     []Change{},
+		Lost:     []Change{},
+		NetValue: after.TotalValue - before.TotalValue,
+	}
+
+	for id, t := range totals {
+		delta := t.after - t.before
+		change := Change{
+			ItemID: id,
+			Name:   t.name,
+			Before: t.before,
+			After:  t.after,
+			Delta:  delta,
+			Value:  delta * t.value,
+		}
+		switch {
+		case delta > 0:
+			diff.Gained = append(diff.Gained, change)
+		case delta < 0 && t.after > 0:
+			diff.Used = append(diff.Used, change)
+		case delta < 0:
+			diff.Lost = append(diff.Lost, change)
+		}
+	}
+
+	for _, changes := range [][]Change{diff.Gained, diff.Used, diff.Lost} {
+		sortByValue(changes)
+	}
+	return diff
+}
+
+// sortByValue orders changes by the size of their value change.
+func sortByValue(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := abs(changes[i].Value), abs(changes[j].Value)
+		if a != b {
+			return a > b
+		}
+		return changes[i].ItemID < changes[j].ItemID
+	})
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package inventory
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	before := Snapshot{
+		ID: "before",
+		Slots: []Slot{
+			{ItemID: "bandage", Name: "Bandage", Quantity: 3, Value: 50},
+			{ItemID: "bandage", Name: "Bandage", Quantity: 2, Value: 50},
+			{ItemID: "shield", Name: "Shield", Quantity: 1, Value: 800},
+			{ItemID: "arc-alloy", Name: "ARC Alloy", Quantity: 1, Value: 200},
+		},
+		TotalValue: 250 + 800 + 200,
+	}
+	after := Snapshot{
+		ID: "after",
+		Slots: []Slot{
+			{ItemID: "bandage", Name: "Bandage", Quantity: 1, Value: 50},
+			{ItemID: "arc-alloy", Name: "ARC Alloy", Quantity: 4, Value: 200},
+			{ItemID: "rusted-gear", Name: "Rusted Gear", Quantity: 2, Value: 30},
+		},
+		TotalValue: 50 + 800 + 60,
+	}
+
+	diff := Compare(before, after)
+
+	if diff.From != "before" || diff.To != "after" {
+		t.Errorf("From/To = %s/%s", diff.From, diff.To)
+	}
+	if diff.NetValue != -340 {
+		t.Errorf("NetValue = %d, want -340", diff.NetValue)
+	}
+
+	check := func(kind string, got []Change, want ...Change) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s = %+v, want %+v", kind, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s[%d] = %+v, want %+v", kind, i, got[i], want[i])
+			}
+		}
+	}
+	check("Gained", diff.Gained,
+		Change{ItemID: "arc-alloy", Name: "ARC Alloy", Before: 1, After: 4, Delta: 3, Value: 600},
+		Change{ItemID: "rusted-gear", Name: "Rusted Gear", Before: 0, After: 2, Delta: 2, Value: 60},
+	)
+	check("Used", diff.Used,
+		Change{ItemID: "bandage", Name: "Bandage", Before: 5, After: 1, Delta: -4, Value: -200},
+	)
+	check("Lost", diff.Lost,
+		Change{ItemID: "shield", Name: "Shield", Before: 1, After: 0, Delta: -1, Value: -800},
+	)
+}
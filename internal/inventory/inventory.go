@@ -0,0 +1,54 @@
+package inventory
+
+import "sort"
+
+// Slot is an identified inventory slot.
+type Slot struct {
+	Row      int    `json:"row"`
+	Col      int    `json:"col"`
+	ItemID   string `json:"itemId"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon,omitempty"`
+	Quantity int    `json:"quantity"`
+	Value    int    `json:"value"`  // per unit
+	Source   string `json:"source"` // what identified it: icon, ocr or both
+}
+
+// StackValue is the value of the whole stack.
+func (s Slot) StackValue() int {
+	return s.Value * s.Quantity
+}
+
+// Summary is the outcome of a grid scan as shown in the overlay.
+type Summary struct {
+	Slots        []Slot `json:"slots"`
+	TotalValue   int    `json:"totalValue"`
+	Unidentified int    `json:"unidentified"` // occupied slots no item was found for
+
+	// Lowest indexes Slots by ascending stack value, holding the
+	// cheapest slots worth selling or dropping first
+	Lowest []int `json:"lowest"`
+}
+
+// Summarize totals the slots and picks the given number of cheapest
+// stacks.
+func Summarize(slots []Slot, unidentified, lowest int) Summary {
+	summary := Summary{
+		Slots:        slots,
+		Unidentified: unidentified,
+		Lowest:       make([]int, 0, min(lowest, len(slots))),
+	}
+
+	order := make([]int, len(slots))
+	for i, slot := range slots {
+		summary.TotalValue += slot.StackValue()
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return slots[order[i]].StackValue() < slots[order[j]].StackValue()
+	})
+	summary.Lowest = append(summary.Lowest, order[:min(lowest, len(order))]...)
+
+	return summary
+}
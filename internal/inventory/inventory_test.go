@@ -0,0 +1,32 @@
+package inventory
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	slots := []Slot{
+		{Row: 0, Col: 0, ItemID: "arc-alloy", Quantity: 3, Value: 200},
+		{Row: 0, Col: 1, ItemID: "rusted-gear", Quantity: 1, Value: 50},
+		{Row: 0, Col: 2, ItemID: "combat-knife-ii", Quantity: 1, Value: 1500},
+		{Row: 1, Col: 0, ItemID: "fabric", Quantity: 10, Value: 5},
+	}
+
+	summary := Summarize(slots, 2, 2)
+
+	if summary.TotalValue != 600+50+1500+50 {
+		t.Errorf("TotalValue = %d, want 2200", summary.TotalValue)
+	}
+	if summary.Unidentified != 2 {
+		t.Errorf("Unidentified = %d, want 2", summary.Unidentified)
+	}
+	// Equal stack values keep grid order
+	if want := []int{1, 3}; !slices.Equal(summary.Lowest, want) {
+		t.Errorf("Lowest = %v, want %v", summary.Lowest, want)
+	}
+
+	if got := Summarize(slots[:1], 0, 5).Lowest; !slices.Equal(got, []int{0}) {
+		t.Errorf("Lowest with fewer slots than asked = %v, want [0]", got)
+	}
+}
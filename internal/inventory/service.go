@@ -0,0 +1,105 @@
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"arc-scanner/internal/config"
+)
+
+// ErrUnavailable is returned when there is no snapshot store.
+var ErrUnavailable = errors.New("inventory snapshots unavailable")
+
+// Service exposes the snapshots to the frontend as bound methods. It is
+// bound before the settings are loaded, so it reads them when called.
+type Service struct {
+	store     *Store // nil if the snapshot directory is unusable
+	exportDir string
+	settings  func() config.InventorySettings
+}
+
+// NewService serves the snapshots in store and writes exports to
+// exportDir. store may be nil, making every method fail.
+func NewService(store *Store, exportDir string, settings func() config.InventorySettings) *Service {
+	return &Service{
+		store:     store,
+		exportDir: exportDir,
+		settings:  settings,
+	}
+}
+
+// ListSnapshots returns the saved inventory snapshots, newest first
+func (s *Service) ListSnapshots() ([]SnapshotInfo, error) {
+	if s.store == nil {
+		return nil, ErrUnavailable
+	}
+	return s.store.List()
+}
+
+// DiffSnapshots compares two inventory snapshots by ID
+func (s *Service) DiffSnapshots(fromID, toID string) (Diff, error) {
+	if s.store == nil {
+		return Diff{}, ErrUnavailable
+	}
+
+	from, err := s.store.Load(fromID)
+	if err != nil {
+		return Diff{}, err
+	}
+	to, err := s.store.Load(toID)
+	if err != nil {
+		return Diff{}, err
+	}
+	return Compare(from, to), nil
+}
+
+// GetLastRaid diffs the newest inventory snapshot against the newest one
+// taken at least inventory.raidGapMinutes before it. It returns
+// ErrSnapshotNotFound until there is such a pair.
+func (s *Service) GetLastRaid() (Diff, error) {
+	if s.store == nil {
+		return Diff{}, ErrUnavailable
+	}
+	gap := time.Duration(s.settings().RaidGapMinutes) * time.Minute
+	return s.store.LastRaid(gap)
+}
+
+// ExportSnapshot writes an inventory snapshot as JSON or CSV to the
+// export directory and returns the file path. An empty ID exports the
+// newest snapshot.
+func (s *Service) ExportSnapshot(id, format string) (string, error) {
+	if s.store == nil {
+		return "", ErrUnavailable
+	}
+
+	if id == "" {
+		infos, err := s.store.List()
+		if err != nil {
+			return "", err
+		}
+		if len(infos) == 0 {
+			return "", ErrSnapshotNotFound
+		}
+		id = infos[0].ID
+	}
+
+	snapshot, err := s.store.Load(id)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, snapshot, format); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.exportDir, "snapshot-"+snapshot.ID+"."+format)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,290 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSnapshotNotFound is returned for unknown snapshot IDs.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// idLayout names snapshots, in UTC, so they sort chronologically.
+const idLayout = "20060102-150405.000"
+
+const snapshotExt = ".json"
+
+// Export formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Snapshot is the inventory as scanned at one point in time.
+type Snapshot struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	Slots        []Slot    `json:"slots"`
+	TotalValue   int       `json:"totalValue"`
+	Unidentified int       `json:"unidentified"`
+}
+
+// SnapshotInfo names a snapshot without reading it.
+type SnapshotInfo struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+// Store keeps snapshots as one JSON file each under dir. Listing and
+// finding the newest snapshots only reads file names.
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save stores a grid scan taken at t as a new snapshot.
+func (s *Store) Save(summary Summary, t time.Time) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := Snapshot{
+		ID:           s.newID(t),
+		Time:         t,
+		Slots:        summary.Slots,
+		TotalValue:   summary.TotalValue,
+		Unidentified: summary.Unidentified,
+	}
+	if snapshot.Slots == nil {
+		snapshot.Slots = []Slot{}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(s.path(snapshot.ID), data, 0o644); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// newID names a snapshot after t, adding a zero-padded suffix that keeps
+// the name order when several land in the same millisecond.
+func (s *Store) newID(t time.Time) string {
+	base := t.UTC().Format(idLayout)
+	id := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(s.path(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%03d", base, i)
+	}
+}
+
+// List returns every snapshot, newest first.
+func (s *Store) List() ([]SnapshotInfo, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SnapshotInfo, len(ids))
+	for i, id := range ids {
+		infos[i] = SnapshotInfo{ID: id, Time: idTime(id)}
+	}
+	return infos, nil
+}
+
+// ids returns the IDs of all snapshots, newest first. Files not named
+// like a snapshot are ignored.
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), snapshotExt)
+		if entry.IsDir() || !ok || idTime(id).IsZero() {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	// A same-millisecond suffix sorts after its base ID, as it should
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// idTime returns the time a snapshot ID was named after, or the zero
+// time if id isn't a snapshot ID.
+func idTime(id string) time.Time {
+	if len(id) < len(idLayout) {
+		return time.Time{}
+	}
+	t, err := time.Parse(idLayout, id[:len(idLayout)])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Load reads a snapshot by ID.
+func (s *Store) Load(id string) (Snapshot, error) {
+	// IDs are file names, so anything with a separator is bogus
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return Snapshot{}, ErrSnapshotNotFound
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return Snapshot{}, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Latest returns the newest n snapshots, newest first.
+func (s *Store) Latest(n int) ([]Snapshot, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, min(n, len(ids)))
+	for _, id := range ids[:min(n, len(ids))] {
+		snapshot, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// LastRaid diffs the newest snapshot against the newest one taken at
+// least minGap before it. Rescanning the stash right after a scan then
+// still compares against the inventory from before the raid. It returns
+// ErrSnapshotNotFound when there is no such pair.
+func (s *Store) LastRaid(minGap time.Duration) (Diff, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return Diff{}, err
+	}
+	if len(ids) < 2 {
+		return Diff{}, ErrSnapshotNotFound
+	}
+
+	newest := idTime(ids[0])
+	for _, id := range ids[1:] {
+		if newest.Sub(idTime(id)) < minGap {
+			continue
+		}
+		before, err := s.Load(id)
+		if err != nil {
+			return Diff{}, err
+		}
+		after, err := s.Load(ids[0])
+		if err != nil {
+			return Diff{}, err
+		}
+		return Compare(before, after), nil
+	}
+	return Diff{}, ErrSnapshotNotFound
+}
+
+// Prune deletes all but the newest keep snapshots and returns how many
+// were deleted. keep <= 0 keeps everything.
+func (s *Store) Prune(keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.ids()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range ids[min(keep, len(ids)):] {
+		if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+snapshotExt)
+}
+
+// Export writes a snapshot as JSON, or as CSV with one row per slot.
+func Export(w io.Writer, snapshot Snapshot, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(snapshot); err != nil {
+			return fmt.Errorf("failed to export snapshot: %w", err)
+		}
+		return nil
+	case FormatCSV:
+		return exportCSV(w, snapshot)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func exportCSV(w io.Writer, snapshot Snapshot) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "row", "col", "item_id", "name", "quantity", "value", "stack_value"})
+
+	at := snapshot.Time.Format(time.RFC3339)
+	for _, slot := range snapshot.Slots {
+		cw.Write([]string{
+			at,
+			strconv.Itoa(slot.Row + 1),
+			strconv.Itoa(slot.Col + 1),
+			slot.ItemID,
+			slot.Name,
+			strconv.Itoa(slot.Quantity),
+			strconv.Itoa(slot.Value),
+			strconv.Itoa(slot.StackValue()),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	return nil
+}
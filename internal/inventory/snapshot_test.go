@@ -0,0 +1,215 @@
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_SaveListLoad(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	t0 := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	first, err := store.Save(Summarize([]Slot{{ItemID: "arc-alloy", Quantity: 2, Value: 100}}, 0, 5), t0)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// Same millisecond gets its own ID
+	second, err := store.Save(Summarize(nil, 1, 5), t0)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("snapshots share ID %s", first.ID)
+	}
+	third, err := store.Save(Summarize(nil, 0, 5), t0.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	infos, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(infos) != 3 || infos[0].ID != third.ID || infos[2].ID != first.ID {
+		t.Errorf("List = %+v, want newest first", infos)
+	}
+
+	loaded, err := store.Load(first.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.TotalValue != 200 || len(loaded.Slots) != 1 || !loaded.Time.Equal(t0) {
+		t.Errorf("Load = %+v, want the first snapshot", loaded)
+	}
+
+	latest, err := store.Latest(2)
+	if err != nil || len(latest) != 2 || latest[0].ID != third.ID {
+		t.Errorf("Latest(2) = %+v, %v", latest, err)
+	}
+
+	// Stray files are not snapshots
+	os.WriteFile(filepath.Join(store.dir, "notes.json"), []byte("{}"), 0o644)
+	if infos, _ := store.List(); len(infos) != 3 || !infos[0].Time.Equal(t0.Add(time.Hour)) {
+		t.Errorf("List with a stray file = %+v", infos)
+	}
+
+	for _, id := range []string{"missing", "../escape", ""} {
+		if _, err := store.Load(id); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("Load(%q) error = %v, want ErrSnapshotNotFound", id, err)
+		}
+	}
+}
+
+func TestStore_SameMillisecond(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	// More than nine collisions, so unpadded suffixes would sort -10
+	// before -2
+	t0 := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	var last Snapshot
+	for i := 0; i < 12; i++ {
+		if last, err = store.Save(Summarize(nil, i, 5), t0); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	infos, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(infos) != 12 || infos[0].ID != last.ID {
+		t.Errorf("newest listed = %s, want the last saved %s", infos[0].ID, last.ID)
+	}
+}
+
+func TestStore_LastRaid(t *testing.T) {
+	t0 := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	before := Summarize([]Slot{{ItemID: "arc-alloy", Name: "ARC Alloy", Quantity: 2, Value: 100}}, 0, 5)
+	after := Summarize([]Slot{{ItemID: "arc-alloy", Name: "ARC Alloy", Quantity: 5, Value: 100}}, 0, 5)
+
+	tests := []struct {
+		name    string
+		scans   map[time.Duration]Summary // offset from t0
+		wantNet int
+		wantErr bool
+	}{
+		{
+			name:    "one scan",
+			scans:   map[time.Duration]Summary{0: before},
+			wantErr: true,
+		},
+		{
+			name:    "before and after a raid",
+			scans:   map[time.Duration]Summary{0: before, 30 * time.Minute: after},
+			wantNet: 300,
+		},
+		{
+			name:    "rescanned right after",
+			scans:   map[time.Duration]Summary{0: before, 30 * time.Minute: after, 31 * time.Minute: after},
+			wantNet: 300,
+		},
+		{
+			name:    "only scans minutes apart",
+			scans:   map[time.Duration]Summary{0: before, 2 * time.Minute: after},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for offset, summary := range tt.scans {
+				if _, err := store.Save(summary, t0.Add(offset)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			diff, err := store.LastRaid(10 * time.Minute)
+			if tt.wantErr {
+				if !errors.Is(err, ErrSnapshotNotFound) {
+					t.Errorf("LastRaid error = %v, want ErrSnapshotNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LastRaid failed: %v", err)
+			}
+			if diff.NetValue != tt.wantNet {
+				t.Errorf("NetValue = %d, want %d", diff.NetValue, tt.wantNet)
+			}
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		if _, err := store.Save(Summarize(nil, 0, 5), t0.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := store.Prune(2)
+	if err != nil || removed != 3 {
+		t.Fatalf("Prune(2) = %d, %v, want 3", removed, err)
+	}
+	infos, _ := store.List()
+	if len(infos) != 2 || !infos[1].Time.Equal(t0.Add(3*time.Hour)) {
+		t.Errorf("kept %+v, want the two newest", infos)
+	}
+
+	if removed, _ := store.Prune(0); removed != 0 {
+		t.Errorf("Prune(0) removed %d, want none", removed)
+	}
+}
+
+func TestExport(t *testing.T) {
+	snapshot := Snapshot{
+		ID:   "20261018-200000.000",
+		Time: time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC),
+		Slots: []Slot{
+			{Row: 0, Col: 1, ItemID: "arc-alloy", Name: "ARC Alloy, refined", Quantity: 3, Value: 200},
+		},
+		TotalValue: 600,
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, snapshot, FormatCSV); err != nil {
+		t.Fatalf("Export csv failed: %v", err)
+	}
+	want := "time,row,col,item_id,name,quantity,value,stack_value\n" +
+		"2026-10-18T20:00:00Z,1,2,arc-alloy,\"ARC Alloy, refined\",3,200,600\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Export(&buf, snapshot, FormatJSON); err != nil {
+		t.Fatalf("Export json failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"totalValue": 600`) {
+		t.Errorf("json = %s", buf.String())
+	}
+
+	if err := Export(&buf, snapshot, "xml"); err == nil {
+		t.Error("Export xml succeeded, want error")
+	}
+}
@@ -0,0 +1,117 @@
+package items
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// cacheVersion is bumped whenever the cache layout changes. Older
+// caches are treated as corrupted and refetched.
+const cacheVersion = 1
+
+// cacheFile is the binary cache layout. Items are normalized: UsedIn
+// entries point at other items by ID instead of embedding a full copy.
+// Source names the item source the items came from; caches written
+// before it was added decode with an empty one.
+type cacheFile struct {
+	Version int
+	Source  string
+	Items   []cachedItem
+}
+
+type cachedItem struct {
+	ID                string
+	Name              string
+	Value             int
+	Icon              string
+	RecycleComponents []RecycleEntry
+	UsedIn            []usedInRef
+}
+
+type usedInRef struct {
+	Quantity int
+	ItemID   string
+}
+
+func encodeCache(w io.Writer, items []Item, source string) error {
+	file := cacheFile{
+		Version: cacheVersion,
+		Source:  source,
+		Items:   make([]cachedItem, len(items)),
+	}
+
+	for i, item := range items {
+		cached := cachedItem{
+			ID:    item.ID,
+			Name:  item.Name,
+			Value: item.Value,
+			Icon:  item.Icon,
+		}
+		if item.RecycleComponents != nil {
+			cached.RecycleComponents = *item.RecycleComponents
+		}
+		if item.UsedIn != nil {
+			cached.UsedIn = make([]usedInRef, len(*item.UsedIn))
+			for j, entry := range *item.UsedIn {
+				cached.UsedIn[j] = usedInRef{Quantity: entry.Quantity, ItemID: entry.Item.ID}
+			}
+		}
+		file.Items[i] = cached
+	}
+
+	return gob.NewEncoder(w).Encode(file)
+}
+
+func decodeCache(r io.Reader) ([]Item, string, error) {
+	var file cacheFile
+	if err := gob.NewDecoder(r).Decode(&file); err != nil {
+		return nil, "", err
+	}
+	if file.Version != cacheVersion {
+		return nil, "", fmt.Errorf("cache version %d, want %d", file.Version, cacheVersion)
+	}
+
+	items := make([]Item, len(file.Items))
+	for i, cached := range file.Items {
+		items[i] = Item{
+			ID:    cached.ID,
+			Name:  cached.Name,
+			Value: cached.Value,
+			Icon:  cached.Icon,
+		}
+		if len(cached.RecycleComponents) > 0 {
+			components := cached.RecycleComponents
+			items[i].RecycleComponents = &components
+		}
+	}
+
+	// Resolve references once every item exists. Referenced items are
+	// shallow copies so the graph can't recurse.
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[item.ID] = i
+	}
+
+	for i, cached := range file.Items {
+		if len(cached.UsedIn) == 0 {
+			continue
+		}
+		usedIn := make([]UsedInEntry, len(cached.UsedIn))
+		for j, ref := range cached.UsedIn {
+			target := Item{ID: ref.ItemID}
+			if k, ok := index[ref.ItemID]; ok {
+				target = Item{
+					ID:    items[k].ID,
+					Name:  items[k].Name,
+					Value: items[k].Value,
+					Icon:  items[k].Icon,
+				}
+			}
+			usedIn[j] = UsedInEntry{Quantity: ref.Quantity, Item: target}
+		}
+		items[i].UsedIn = &usedIn
+	}
+
+	return items, file.Source, nil
+}
@@ -0,0 +1,43 @@
+//go:build ignore
+
+// gen_snapshot refreshes snapshot.json from the MetaForge API.
+// Run it with `go generate ./internal/items` before a release build.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/items"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	list, err := items.NewMetaForgeSource(config.MetaForgeAPIBase).Load(ctx)
+	if err != nil {
+		log.Fatalf("failed to fetch items: %v", err)
+	}
+	if len(list) == 0 {
+		log.Fatal("API returned no items, keeping existing snapshot")
+	}
+
+	data, err := json.Marshal(items.Snapshot{
+		GeneratedAt: time.Now().UTC(),
+		Items:       list,
+	})
+	if err != nil {
+		log.Fatalf("failed to encode snapshot: %v", err)
+	}
+
+	if err := os.WriteFile("snapshot.json", data, 0o644); err != nil {
+		log.Fatalf("failed to write snapshot: %v", err)
+	}
+
+	log.Printf("snapshot written with %d items", len(list))
+}
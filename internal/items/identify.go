@@ -0,0 +1,66 @@
+package items
+
+import "sort"
+
+// Weights and thresholds for combining identification sources. OCR
+// names the item directly, so it outweighs a look-alike icon.
+const (
+	ocrWeight  = 1.0
+	iconWeight = 0.6
+
+	// An item only icons point to must be backed this strongly and lead
+	// the runner-up by this much
+	minIdentifyScore  = 0.3
+	minIdentifyMargin = 0.1
+)
+
+// Candidate is a possible identification and how strongly one source
+// supports it, between 0 and 1.
+type Candidate struct {
+	ID    string
+	Score float64
+}
+
+// Combine merges the candidates of the OCR matcher and of icon matching
+// by summing their weighted scores per item. A winner the text names is
+// always reported, as before icon matching. One only icons point to must
+// be backed strongly enough and clearly ahead of the next item, which
+// keeps tiers sharing an icon from being guessed when the text is
+// unreadable.
+func Combine(ocr, icon []Candidate) (Candidate, bool) {
+	totals := make(map[string]float64)
+	named := make(map[string]bool)
+	for _, c := range ocr {
+		totals[c.ID] += ocrWeight * c.Score
+		named[c.ID] = true
+	}
+	for _, c := range icon {
+		totals[c.ID] += iconWeight * c.Score
+	}
+	if len(totals) == 0 {
+		return Candidate{}, false
+	}
+
+	ranked := make([]Candidate, 0, len(totals))
+	for id, total := range totals {
+		ranked = append(ranked, Candidate{ID: id, Score: total})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	best := ranked[0]
+	if named[best.ID] {
+		return best, true
+	}
+	if best.Score < minIdentifyScore {
+		return Candidate{}, false
+	}
+	if len(ranked) > 1 && best.Score-ranked[1].Score < minIdentifyMargin {
+		return Candidate{}, false
+	}
+	return best, true
+}
@@ -0,0 +1,76 @@
+package items
+
+import "testing"
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		name   string
+		ocr    []Candidate
+		icon   []Candidate
+		wantID string
+		wantOK bool
+	}{
+		{
+			name:   "ocr alone",
+			ocr:    []Candidate{{ID: "arc-alloy", Score: 0.9}},
+			wantID: "arc-alloy",
+			wantOK: true,
+		},
+		{
+			name:   "weak ocr alone",
+			ocr:    []Candidate{{ID: "arc-alloy", Score: 0.2}},
+			wantID: "arc-alloy",
+			wantOK: true,
+		},
+		{
+			name:   "icon rescues unreadable text",
+			icon:   []Candidate{{ID: "arc-alloy", Score: 0.9}, {ID: "rusted-gear", Score: 0.2}},
+			wantID: "arc-alloy",
+			wantOK: true,
+		},
+		{
+			name:   "ocr breaks a tie between tiers sharing an icon",
+			ocr:    []Candidate{{ID: "combat-knife-ii", Score: 0.5}},
+			icon:   []Candidate{{ID: "combat-knife-i", Score: 0.9}, {ID: "combat-knife-ii", Score: 0.9}},
+			wantID: "combat-knife-ii",
+			wantOK: true,
+		},
+		{
+			name:   "tied icons without text",
+			icon:   []Candidate{{ID: "combat-knife-i", Score: 0.9}, {ID: "combat-knife-ii", Score: 0.9}},
+			wantOK: false,
+		},
+		{
+			name:   "weak icon match",
+			icon:   []Candidate{{ID: "arc-alloy", Score: 0.3}},
+			wantOK: false,
+		},
+		{
+			name:   "confident ocr beats a look-alike icon",
+			ocr:    []Candidate{{ID: "arc-alloy", Score: 0.9}},
+			icon:   []Candidate{{ID: "rusted-gear", Score: 0.95}},
+			wantID: "arc-alloy",
+			wantOK: true,
+		},
+		{
+			name:   "strong icon beats a weak misread",
+			ocr:    []Candidate{{ID: "rusted-gear", Score: 0.2}},
+			icon:   []Candidate{{ID: "arc-alloy", Score: 0.9}},
+			wantID: "arc-alloy",
+			wantOK: true,
+		},
+		{
+			name:   "nothing",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Combine(tt.ocr, tt.icon)
+			if ok != tt.wantOK || got.ID != tt.wantID {
+				t.Errorf("Combine = %+v, %v, want %s, %v", got, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package items
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
+
+	"arc-scanner/internal/ocr"
 )
 
 // romanSuffixes are common Roman numeral suffixes for item tiers.
@@ -47,32 +50,145 @@ func CleanOCRText(text string) []string {
 }
 
 func (m *Matcher) FindItem(tokens []string) (Item, error) {
+	item, _, err := m.FindItemScored(tokens)
+	return item, err
+}
+
+// Match scores used by FindItemScored.
+const (
+	matchScoreWhole   = 1.0 // name lines up with token boundaries
+	matchScorePartial = 0.6 // name only found inside longer tokens
+)
+
+// FindItemScored works like FindItem and also rates how cleanly the
+// name was read, between 0 and 1. A name glued to OCR noise, e.g.
+// "XARC ALLOYS", scores lower than one standing on its own.
+func (m *Matcher) FindItemScored(tokens []string) (Item, float64, error) {
+	item, _, score, err := m.findItem(tokens)
+	return item, score, err
+}
+
+// findItem also returns the name the item was matched by.
+func (m *Matcher) findItem(tokens []string) (Item, string, float64, error) {
 	textJoined := strings.Join(tokens, " ")
 	var bestMatch Item
+	var bestName string
 
 	for _, item := range m.items {
-		// Convert item ID to search pattern (e.g., "crafting-manual" -> "CRAFTING MANUAL")
-		searchName := strings.ToUpper(strings.ReplaceAll(item.ID, "-", " "))
-		searchName = strings.ReplaceAll(searchName, "RECIPE", "")
-
-		if strings.Contains(textJoined, searchName) {
-			bestMatch = item
-
-			// Check for Roman numeral suffix for tiered items
-			for _, suffix := range romanSuffixes {
-				if strings.Contains(textJoined, searchName+suffix) {
-					bestMatch = item
-					break
+		for _, searchName := range searchNames(item) {
+			if strings.Contains(textJoined, searchName) {
+				bestMatch = item
+				bestName = searchName
+
+				// Check for Roman numeral suffix for tiered items
+				for _, suffix := range romanSuffixes {
+					if strings.Contains(textJoined, searchName+suffix) {
+						bestMatch = item
+						bestName = searchName + suffix
+						break
+					}
 				}
 			}
 		}
 	}
 
-	if bestMatch.ID != "" {
-		return bestMatch, nil
+	if bestMatch.ID == "" {
+		return Item{}, "", 0, ErrItemNotFound
+	}
+
+	name := strings.TrimSpace(bestName)
+	if strings.Contains(" "+textJoined+" ", " "+name+" ") {
+		return bestMatch, name, matchScoreWhole, nil
+	}
+	return bestMatch, name, matchScorePartial, nil
+}
+
+// Token is a cleaned OCR word with the confidence it was read with.
+type Token struct {
+	Text       string
+	Confidence float64 // 0-100
+}
+
+// CleanOCRWords cleans the words of an OCR read like CleanOCRText,
+// keeping each word's confidence. Reads without word data fall back to
+// the plain text, every token taking the overall confidence.
+func CleanOCRWords(result ocr.Result) []Token {
+	words := result.Words()
+	if len(words) == 0 {
+		texts := CleanOCRText(result.Text)
+		tokens := make([]Token, len(texts))
+		for i, text := range texts {
+			tokens[i] = Token{Text: text, Confidence: result.Confidence}
+		}
+		return tokens
+	}
+
+	var tokens []Token
+	for _, word := range words {
+		for _, text := range CleanOCRText(word.Text) {
+			tokens = append(tokens, Token{Text: text, Confidence: word.Confidence})
+		}
+	}
+	return tokens
+}
+
+// Match finds the item in an OCR read. The score is FindItemScored's,
+// weighted by how confidently the words of the name were read, so a
+// clean name in a noisy description still rates high.
+func (m *Matcher) Match(result ocr.Result) (Item, float64, error) {
+	tokens := CleanOCRWords(result)
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.Text
+	}
+
+	item, name, score, err := m.findItem(texts)
+	if err != nil {
+		return Item{}, 0, err
 	}
+	return item, score * nameConfidence(tokens, name, result.Confidence) / 100, nil
+}
+
+// nameConfidence averages the confidence of the tokens that make up
+// name, falling back to def when none can be told apart.
+func nameConfidence(tokens []Token, name string, def float64) float64 {
+	nameWords := strings.Fields(name)
+	sum, n := 0.0, 0
+	for _, token := range tokens {
+		for _, word := range nameWords {
+			if strings.Contains(token.Text, word) || (len(token.Text) > 1 && strings.Contains(word, token.Text)) {
+				sum += token.Confidence
+				n++
+				break
+			}
+		}
+	}
+	if n == 0 {
+		return def
+	}
+	return sum / float64(n)
+}
+
+// Score rates the best match of an OCR read, returning its item ID. It
+// has the signature of scanner.Scorer.
+func (m *Matcher) Score(result ocr.Result) (string, float64, bool) {
+	item, score, err := m.Match(result)
+	return item.ID, score, err == nil
+}
+
+// searchNames returns the uppercase patterns an item is matched by: its
+// ID (e.g., "crafting-manual" -> "CRAFTING MANUAL") plus any aliases.
+func searchNames(item Item) []string {
+	searchName := strings.ToUpper(strings.ReplaceAll(item.ID, "-", " "))
+	searchName = strings.ReplaceAll(searchName, "RECIPE", "")
 
-	return Item{}, ErrItemNotFound
+	names := []string{searchName}
+	for _, alias := range item.Aliases {
+		if alias = strings.ToUpper(strings.TrimSpace(alias)); alias != "" {
+			names = append(names, alias)
+		}
+	}
+	return names
 }
 
 // ParseQuantity extracts the stack quantity from OCR text.
@@ -90,3 +206,26 @@ func ParseQuantity(text string) int {
 	}
 	return 1
 }
+
+// quantityPattern matches a stack count word such as "5/10".
+var quantityPattern = regexp.MustCompile(`^(\d+)/\d+$`)
+
+// ParseQuantityWords extracts the stack quantity from an OCR read,
+// trusting the most confidently read count when OCR found several. It
+// falls back to ParseQuantity when no word looks like a count.
+func ParseQuantityWords(result ocr.Result) int {
+	quantity, best := 0, -1.0
+	for _, word := range result.Words() {
+		match := quantityPattern.FindStringSubmatch(word.Text)
+		if match == nil || word.Confidence <= best {
+			continue
+		}
+		if num, err := strconv.Atoi(match[1]); err == nil && num > 0 {
+			quantity, best = num, word.Confidence
+		}
+	}
+	if best < 0 {
+		return ParseQuantity(result.Text)
+	}
+	return quantity
+}
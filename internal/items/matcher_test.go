@@ -1,7 +1,10 @@
 package items
 
 import (
+	"math"
 	"testing"
+
+	"arc-scanner/internal/ocr"
 )
 
 func TestCleanOCRText(t *testing.T) {
@@ -121,6 +124,7 @@ func TestMatcher_FindItem(t *testing.T) {
 		{ID: "pipe-wrench", Name: "Pipe Wrench", Value: 50},
 		{ID: "combat-knife-i", Name: "Combat Knife I", Value: 200},
 		{ID: "combat-knife-ii", Name: "Combat Knife II", Value: 300},
+		{ID: "arc-alloy", Name: "ARC Alloy", Value: 80, Aliases: []string{"ARG ALLOY"}},
 	}
 
 	matcher := NewMatcher(testItems)
@@ -146,6 +150,11 @@ func TestMatcher_FindItem(t *testing.T) {
 			tokens:     []string{"COMBAT", "KNIFE", "II"},
 			expectedID: "combat-knife-ii",
 		},
+		{
+			name:       "match by alias",
+			tokens:     []string{"ARG", "ALLOY"},
+			expectedID: "arc-alloy",
+		},
 		{
 			name:        "no match",
 			tokens:      []string{"UNKNOWN", "ITEM"},
@@ -181,6 +190,131 @@ func TestMatcher_FindItem(t *testing.T) {
 	}
 }
 
+func TestMatcher_FindItemScored(t *testing.T) {
+	matcher := NewMatcher([]Item{
+		{ID: "arc-alloy", Name: "ARC Alloy", Value: 80},
+		{ID: "combat-knife-ii", Name: "Combat Knife II", Value: 300},
+	})
+
+	tests := []struct {
+		name      string
+		tokens    []string
+		wantID    string
+		wantScore float64
+	}{
+		{"whole tokens", []string{"ARC", "ALLOY", "3/10"}, "arc-alloy", matchScoreWhole},
+		{"glued to noise", []string{"XARC", "ALLOYS"}, "arc-alloy", matchScorePartial},
+		{"tier suffix", []string{"COMBAT", "KNIFE", "II"}, "combat-knife-ii", matchScoreWhole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, score, err := matcher.FindItemScored(tt.tokens)
+			if err != nil {
+				t.Fatalf("FindItemScored(%v) unexpected error: %v", tt.tokens, err)
+			}
+			if item.ID != tt.wantID || score != tt.wantScore {
+				t.Errorf("FindItemScored(%v) = %s, %v, want %s, %v", tt.tokens, item.ID, score, tt.wantID, tt.wantScore)
+			}
+		})
+	}
+
+	if _, score, err := matcher.FindItemScored([]string{"NOTHING"}); err == nil || score != 0 {
+		t.Errorf("FindItemScored(NOTHING) = %v, %v, want ErrItemNotFound", score, err)
+	}
+}
+
+// ocrLine builds a single-line OCR read from words.
+func ocrLine(words ...ocr.Word) ocr.Result {
+	line := ocr.Line{Words: words}
+	return ocr.Result{
+		Text:       line.Text(),
+		Confidence: line.Confidence(),
+		Blocks:     []ocr.Block{{Lines: []ocr.Line{line}}},
+	}
+}
+
+func TestMatch_WeightsNameConfidence(t *testing.T) {
+	matcher := NewMatcher([]Item{{ID: "arc-alloy", Name: "ARC Alloy"}})
+
+	tests := []struct {
+		name      string
+		result    ocr.Result
+		wantScore float64
+	}{
+		{
+			name: "clean name, noisy description",
+			result: ocrLine(
+				ocr.Word{Text: "ARC", Confidence: 96},
+				ocr.Word{Text: "ALLOY", Confidence: 94},
+				ocr.Word{Text: "USED", Confidence: 20},
+				ocr.Word{Text: "FOR", Confidence: 10},
+			),
+			wantScore: 0.95,
+		},
+		{
+			name: "shaky name",
+			result: ocrLine(
+				ocr.Word{Text: "ARC", Confidence: 50},
+				ocr.Word{Text: "ALLOY", Confidence: 40},
+			),
+			wantScore: 0.45,
+		},
+		{
+			name:      "text only",
+			result:    ocr.Result{Text: "ARC ALLOY", Confidence: 80},
+			wantScore: 0.8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, score, err := matcher.Match(tt.result)
+			if err != nil || item.ID != "arc-alloy" {
+				t.Fatalf("Match = %s, %v, want arc-alloy", item.ID, err)
+			}
+			if math.Abs(score-tt.wantScore) > 0.001 {
+				t.Errorf("score = %v, want %v", score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestParseQuantityWords(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ocr.Result
+		want   int
+	}{
+		{
+			name: "most confident count wins",
+			result: ocrLine(
+				ocr.Word{Text: "8/10", Confidence: 30},
+				ocr.Word{Text: "3/10", Confidence: 90},
+			),
+			want: 3,
+		},
+		{
+			name:   "no count word falls back to text",
+			result: ocrLine(ocr.Word{Text: "5", Confidence: 90}, ocr.Word{Text: "/10", Confidence: 90}),
+			want:   5,
+		},
+		{
+			name:   "no count at all",
+			result: ocrLine(ocr.Word{Text: "ARC", Confidence: 90}),
+			want:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQuantityWords(tt.result); got != tt.want {
+				t.Errorf("ParseQuantityWords = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBuildIndex(t *testing.T) {
 	items := []Item{
 		{ID: "item-1", Name: "Item One", Value: 100},
@@ -7,6 +7,20 @@ type Item struct {
 	Icon              string          `json:"icon"`
 	RecycleComponents *[]RecycleEntry `json:"recycle_components"`
 	UsedIn            *[]UsedInEntry  `json:"used_in"`
+
+	// Set by the overrides layer
+	Aliases []string     `json:"aliases,omitempty"`
+	Notes   string       `json:"notes,omitempty"`
+	Hidden  bool         `json:"hidden,omitempty"`
+	Origin  FieldOrigins `json:"origin"`
+}
+
+// FieldOrigins records where each overridable field's value came from:
+// the name of the item source, or OriginOverride.
+type FieldOrigins struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+	Notes string `json:"notes,omitempty"`
 }
 
 type RecycleEntry struct {
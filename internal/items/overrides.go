@@ -0,0 +1,101 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OriginOverride marks a field whose value came from the user's
+// overrides file rather than the item source.
+const OriginOverride = "override"
+
+// Override replaces or extends the data of a single item. Zero-valued
+// fields leave the source data untouched.
+type Override struct {
+	Value   *int     `json:"value,omitempty" yaml:"value,omitempty"`
+	Name    string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Notes   string   `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Hidden  bool     `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+}
+
+// Overrides maps item IDs to user overrides.
+type Overrides map[string]Override
+
+// LoadOverrides reads a JSON or YAML overrides file, picking the format
+// from the extension. A missing file yields no overrides.
+func LoadOverrides(path string) (Overrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Overrides{}, nil
+		}
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+
+	overrides := Overrides{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &overrides)
+	default:
+		err = json.Unmarshal(data, &overrides)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overrides: %w", err)
+	}
+
+	slog.Info("overrides loaded", "path", path, "count", len(overrides))
+	return overrides, nil
+}
+
+// Apply returns a copy of items with the overrides merged on top.
+// Every item's Origin is filled in, using origin for fields that
+// weren't overridden.
+func (o Overrides) Apply(items []Item, origin string) []Item {
+	known := make(map[string]bool, len(items))
+	merged := make([]Item, len(items))
+	for i, item := range items {
+		known[item.ID] = true
+
+		item.Origin = FieldOrigins{
+			Name:  origin,
+			Value: origin,
+			Notes: origin,
+		}
+
+		if override, ok := o[item.ID]; ok {
+			if override.Value != nil {
+				item.Value = *override.Value
+				item.Origin.Value = OriginOverride
+			}
+			if override.Name != "" {
+				item.Name = override.Name
+				item.Origin.Name = OriginOverride
+			}
+			if override.Notes != "" {
+				item.Notes = override.Notes
+				item.Origin.Notes = OriginOverride
+			}
+			if len(override.Aliases) > 0 {
+				item.Aliases = append(append([]string(nil), item.Aliases...), override.Aliases...)
+			}
+			item.Hidden = override.Hidden
+		}
+
+		merged[i] = item
+	}
+
+	for id := range o {
+		if !known[id] {
+			slog.Warn("override for unknown item", "id", id)
+		}
+	}
+
+	return merged
+}
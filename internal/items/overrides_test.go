@@ -0,0 +1,107 @@
+package items
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverrides_Apply(t *testing.T) {
+	value := 999
+	overrides := Overrides{
+		"pipe-wrench": {Value: &value, Notes: "patched in 1.2"},
+		"old-item":    {Name: "Renamed Item", Aliases: []string{"OLD THING"}, Hidden: true},
+	}
+
+	base := []Item{
+		{ID: "pipe-wrench", Name: "Pipe Wrench", Value: 50},
+		{ID: "old-item", Name: "Old Item", Value: 10},
+		{ID: "untouched", Name: "Untouched", Value: 1},
+	}
+
+	merged := overrides.Apply(base, "metaforge")
+	index := BuildIndex(merged)
+
+	wrench, _ := index.Get("pipe-wrench")
+	if wrench.Value != 999 || wrench.Origin.Value != OriginOverride {
+		t.Errorf("pipe-wrench value = %d (%s), want 999 (override)", wrench.Value, wrench.Origin.Value)
+	}
+	if wrench.Name != "Pipe Wrench" || wrench.Origin.Name != "metaforge" {
+		t.Errorf("pipe-wrench name = %s (%s), want Pipe Wrench (metaforge)", wrench.Name, wrench.Origin.Name)
+	}
+	if wrench.Notes != "patched in 1.2" || wrench.Origin.Notes != OriginOverride {
+		t.Errorf("pipe-wrench notes = %q (%s), want override", wrench.Notes, wrench.Origin.Notes)
+	}
+
+	old, _ := index.Get("old-item")
+	if old.Name != "Renamed Item" || !old.Hidden || len(old.Aliases) != 1 {
+		t.Errorf("old-item = %+v, want renamed, hidden, one alias", old)
+	}
+
+	untouched, _ := index.Get("untouched")
+	if untouched.Origin.Value != "metaforge" {
+		t.Errorf("untouched value origin = %s, want metaforge", untouched.Origin.Value)
+	}
+
+	// The base items must not be modified
+	if base[0].Value != 50 {
+		t.Errorf("Apply modified base items: value = %d", base[0].Value)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "arc-scanner-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "json",
+			file:    "overrides.json",
+			content: `{"pipe-wrench": {"value": 75, "aliases": ["WRENCH"]}}`,
+		},
+		{
+			name:    "yaml",
+			file:    "overrides.yaml",
+			content: "pipe-wrench:\n  value: 75\n  aliases:\n    - WRENCH\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			overrides, err := LoadOverrides(path)
+			if err != nil {
+				t.Fatalf("LoadOverrides failed: %v", err)
+			}
+
+			override, ok := overrides["pipe-wrench"]
+			if !ok || override.Value == nil || *override.Value != 75 {
+				t.Fatalf("LoadOverrides = %+v, want pipe-wrench value 75", overrides)
+			}
+			if len(override.Aliases) != 1 || override.Aliases[0] != "WRENCH" {
+				t.Errorf("aliases = %v, want [WRENCH]", override.Aliases)
+			}
+		})
+	}
+}
+
+func TestLoadOverrides_Missing(t *testing.T) {
+	overrides, err := LoadOverrides(filepath.Join(t.TempDir(), "overrides.json"))
+	if err != nil {
+		t.Fatalf("LoadOverrides failed for missing file: %v", err)
+	}
+	if len(overrides) != 0 {
+		t.Errorf("LoadOverrides returned %d overrides, want 0", len(overrides))
+	}
+}
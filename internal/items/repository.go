@@ -1,13 +1,12 @@
 package items
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-
-	"arc-scanner/internal/config"
 )
 
 type Repository struct {
@@ -20,61 +19,53 @@ func NewRepository(cachePath string) *Repository {
 	}
 }
 
-func (r *Repository) FetchFromAPI() ([]Item, error) {
-	var allItems []Item
-
-	slog.Info("fetching items from API")
-
-	for page := 1; page <= config.APIPageCount; page++ {
-		url := fmt.Sprintf("%s?minimal=true&includeComponents=true&limit=%d&page=%d",
-			config.MetaForgeAPIBase, config.APIPageSize, page)
-
-		slog.Debug("fetching page", "page", page, "url", url)
-
-		resp, err := http.Get(url)
-		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrAPIUnavailable, err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("%w: status %d", ErrAPIUnavailable, resp.StatusCode)
-		}
-
-		var response Response
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-			return nil, fmt.Errorf("failed to decode API response: %w", err)
+// LoadFromCache reads the cached items and the origin to report for
+// them, see CacheOrigin.
+func (r *Repository) LoadFromCache() ([]Item, string, error) {
+	data, err := os.ReadFile(r.cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", err // Let caller handle missing file
 		}
+		return nil, "", fmt.Errorf("failed to read cache: %w", err)
+	}
 
-		allItems = append(allItems, response.Data...)
+	items, source, err := decodeCache(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrCacheCorrupted, err)
 	}
 
-	slog.Info("items fetched from API", "count", len(allItems))
-	return allItems, nil
+	slog.Info("items loaded from cache", "count", len(items), "source", source)
+	return items, CacheOrigin(source), nil
 }
 
-func (r *Repository) LoadFromCache() ([]Item, error) {
-	data, err := os.ReadFile(r.cachePath)
+// SaveToCache writes items in the compact binary cache format along
+// with the name of the item source they came from.
+func (r *Repository) SaveToCache(items []Item, source string) error {
+	file, err := os.Create(r.cachePath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, err // Let caller handle missing file
-		}
-		return nil, fmt.Errorf("failed to read cache: %w", err)
+		return fmt.Errorf("failed to create cache file: %w", err)
 	}
+	defer file.Close()
 
-	var items []Item
-	if err := json.Unmarshal(data, &items); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrCacheCorrupted, err)
+	w := bufio.NewWriter(file)
+	if err := encodeCache(w, items, source); err != nil {
+		return fmt.Errorf("failed to encode items: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
 	}
 
-	slog.Info("items loaded from cache", "count", len(items))
-	return items, nil
+	slog.Info("items saved to cache", "path", r.cachePath, "count", len(items))
+	return nil
 }
 
-func (r *Repository) SaveToCache(items []Item) error {
-	file, err := os.Create(r.cachePath)
+// ExportJSON writes items as indented JSON for debugging. The result
+// can be loaded back with a file item source.
+func (r *Repository) ExportJSON(items []Item, path string) error {
+	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("failed to create cache file: %w", err)
+		return fmt.Errorf("failed to create export file: %w", err)
 	}
 	defer file.Close()
 
@@ -85,10 +76,19 @@ func (r *Repository) SaveToCache(items []Item) error {
 		return fmt.Errorf("failed to encode items: %w", err)
 	}
 
-	slog.Info("items saved to cache", "path", r.cachePath, "count", len(items))
+	slog.Info("items exported", "path", path, "count", len(items))
 	return nil
 }
 
+// CacheOrigin is the origin of items read from a cache that source
+// filled, e.g. "cache (metaforge)", or "cache" when it isn't known.
+func CacheOrigin(source string) string {
+	if source == "" {
+		return "cache"
+	}
+	return "cache (" + source + ")"
+}
+
 func (r *Repository) CachePath() string {
 	return r.cachePath
 }
@@ -1,6 +1,8 @@
 package items
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -24,7 +26,7 @@ func TestRepository_SaveAndLoadCache(t *testing.T) {
 	}
 
 	// Test SaveToCache
-	if err := repo.SaveToCache(items); err != nil {
+	if err := repo.SaveToCache(items, "metaforge"); err != nil {
 		t.Fatalf("SaveToCache failed: %v", err)
 	}
 
@@ -34,10 +36,13 @@ func TestRepository_SaveAndLoadCache(t *testing.T) {
 	}
 
 	// Test LoadFromCache
-	loaded, err := repo.LoadFromCache()
+	loaded, origin, err := repo.LoadFromCache()
 	if err != nil {
 		t.Fatalf("LoadFromCache failed: %v", err)
 	}
+	if origin != "cache (metaforge)" {
+		t.Errorf("LoadFromCache origin = %q, want the source it was filled from", origin)
+	}
 
 	if len(loaded) != len(items) {
 		t.Errorf("LoadFromCache returned %d items, want %d", len(loaded), len(items))
@@ -68,7 +73,7 @@ func TestRepository_CacheNotExists(t *testing.T) {
 		t.Error("CacheExists returned true for nonexistent file")
 	}
 
-	_, err = repo.LoadFromCache()
+	_, _, err = repo.LoadFromCache()
 	if err == nil {
 		t.Error("LoadFromCache should return error for nonexistent file")
 	}
@@ -90,7 +95,7 @@ func TestRepository_CorruptedCache(t *testing.T) {
 
 	repo := NewRepository(cachePath)
 
-	_, err = repo.LoadFromCache()
+	_, _, err = repo.LoadFromCache()
 	if err == nil {
 		t.Error("LoadFromCache should return error for corrupted file")
 	}
@@ -132,11 +137,11 @@ func TestRepository_SaveToCache_WithComplexItems(t *testing.T) {
 	}
 
 	// Save and reload
-	if err := repo.SaveToCache(items); err != nil {
+	if err := repo.SaveToCache(items, "metaforge"); err != nil {
 		t.Fatalf("SaveToCache failed: %v", err)
 	}
 
-	loaded, err := repo.LoadFromCache()
+	loaded, _, err := repo.LoadFromCache()
 	if err != nil {
 		t.Fatalf("LoadFromCache failed: %v", err)
 	}
@@ -159,3 +164,134 @@ func TestRepository_SaveToCache_WithComplexItems(t *testing.T) {
 		t.Errorf("First component ID = %s, want component-1", components[0].Component.ID)
 	}
 }
+
+func TestRepository_UsedInNormalized(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := NewRepository(filepath.Join(tmpDir, "items.cache"))
+
+	usedIn := []UsedInEntry{
+		{Quantity: 3, Item: Item{ID: "gun", Name: "stale name"}},
+		{Quantity: 1, Item: Item{ID: "not-in-cache"}},
+	}
+	items := []Item{
+		{ID: "metal-parts", Name: "Metal Parts", Value: 10, UsedIn: &usedIn},
+		{ID: "gun", Name: "Gun", Value: 500, Icon: "https://example.com/gun.png"},
+	}
+
+	if err := repo.SaveToCache(items, "metaforge"); err != nil {
+		t.Fatalf("SaveToCache failed: %v", err)
+	}
+
+	loaded, _, err := repo.LoadFromCache()
+	if err != nil {
+		t.Fatalf("LoadFromCache failed: %v", err)
+	}
+
+	if loaded[0].UsedIn == nil || len(*loaded[0].UsedIn) != 2 {
+		t.Fatalf("UsedIn = %v, want 2 entries", loaded[0].UsedIn)
+	}
+
+	// References resolve to the cached item, not the embedded copy
+	gun := (*loaded[0].UsedIn)[0]
+	if gun.Quantity != 3 || gun.Item.Name != "Gun" || gun.Item.Value != 500 {
+		t.Errorf("UsedIn[0] = %+v, want 3x Gun (500)", gun)
+	}
+
+	missing := (*loaded[0].UsedIn)[1]
+	if missing.Item.ID != "not-in-cache" {
+		t.Errorf("UsedIn[1].Item.ID = %s, want not-in-cache", missing.Item.ID)
+	}
+
+	if loaded[1].UsedIn != nil || loaded[1].RecycleComponents != nil {
+		t.Error("empty nested lists should load as nil")
+	}
+}
+
+func TestRepository_ExportJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := NewRepository(filepath.Join(tmpDir, "items.cache"))
+	exportPath := filepath.Join(tmpDir, "items-export.json")
+
+	items := []Item{{ID: "test-item-1", Name: "Test Item 1", Value: 100}}
+	if err := repo.ExportJSON(items, exportPath); err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+
+	loaded, err := NewFileSource(exportPath).Load(context.Background())
+	if err != nil {
+		t.Fatalf("exported JSON not loadable: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Value != 100 {
+		t.Errorf("exported items = %v, want test-item-1 (100)", loaded)
+	}
+}
+
+// benchmarkItems builds a database shaped like the MetaForge data:
+// ~600 items, each used in a few others and recycling into a few parts.
+func benchmarkItems() []Item {
+	const count = 600
+	items := make([]Item, count)
+	for i := range items {
+		items[i] = Item{
+			ID:    fmt.Sprintf("item-%d", i),
+			Name:  fmt.Sprintf("Item %d", i),
+			Value: i * 10,
+			Icon:  fmt.Sprintf("https://cdn.example.com/items/item-%d.webp", i),
+		}
+	}
+	for i := range items {
+		recycle := []RecycleEntry{
+			{Quantity: 2, Component: Component{ID: items[(i+1)%count].ID, Name: items[(i+1)%count].Name}},
+			{Quantity: 1, Component: Component{ID: items[(i+2)%count].ID, Name: items[(i+2)%count].Name}},
+		}
+		usedIn := []UsedInEntry{
+			{Quantity: 1, Item: items[(i+3)%count]},
+			{Quantity: 4, Item: items[(i+5)%count]},
+			{Quantity: 2, Item: items[(i+7)%count]},
+		}
+		items[i].RecycleComponents = &recycle
+		items[i].UsedIn = &usedIn
+	}
+	return items
+}
+
+func BenchmarkCacheLoad_JSON(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "items.json")
+	if err := NewRepository("").ExportJSON(benchmarkItems(), path); err != nil {
+		b.Fatalf("ExportJSON failed: %v", err)
+	}
+	source := NewFileSource(path)
+	reportSize(b, path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := source.Load(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCacheLoad_Binary(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "items.cache")
+	repo := NewRepository(path)
+	if err := repo.SaveToCache(benchmarkItems(), "metaforge"); err != nil {
+		b.Fatalf("SaveToCache failed: %v", err)
+	}
+	reportSize(b, path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := repo.LoadFromCache(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func reportSize(b *testing.B, path string) {
+	b.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(info.Size()), "file-bytes")
+}
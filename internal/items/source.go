@@ -0,0 +1,244 @@
+package items
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"arc-scanner/internal/config"
+)
+
+// ItemSource loads the full item database from somewhere.
+type ItemSource interface {
+	Name() string
+	Load(ctx context.Context) ([]Item, error)
+}
+
+var httpClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// MetaForgeSource pages through the MetaForge items API.
+type MetaForgeSource struct {
+	baseURL string
+}
+
+func NewMetaForgeSource(baseURL string) *MetaForgeSource {
+	if baseURL == "" {
+		baseURL = config.MetaForgeAPIBase
+	}
+	return &MetaForgeSource{
+		baseURL: baseURL,
+	}
+}
+
+func (s *MetaForgeSource) Name() string {
+	return config.SourceMetaForge
+}
+
+func (s *MetaForgeSource) Load(ctx context.Context) ([]Item, error) {
+	var allItems []Item
+
+	slog.Info("fetching items from API")
+
+	for page := 1; page <= config.APIPageCount; page++ {
+		url := fmt.Sprintf("%s?minimal=true&includeComponents=true&limit=%d&page=%d",
+			s.baseURL, config.APIPageSize, page)
+
+		slog.Debug("fetching page", "page", page, "url", url)
+
+		data, err := fetch(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		var response Response
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("failed to decode API response: %w", err)
+		}
+
+		allItems = append(allItems, response.Data...)
+	}
+
+	slog.Info("items fetched from API", "count", len(allItems))
+	return allItems, nil
+}
+
+// FileSource reads items from a local JSON file.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{
+		path: path,
+	}
+}
+
+func (s *FileSource) Name() string {
+	return config.SourceFile
+}
+
+func (s *FileSource) Load(ctx context.Context) ([]Item, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	return decodeItems(data)
+}
+
+// MirrorSource downloads a full item dump from a single URL, such as an
+// internal mirror of the MetaForge data.
+type MirrorSource struct {
+	url string
+}
+
+func NewMirrorSource(url string) *MirrorSource {
+	return &MirrorSource{
+		url: url,
+	}
+}
+
+func (s *MirrorSource) Name() string {
+	return config.SourceMirror
+}
+
+func (s *MirrorSource) Load(ctx context.Context) ([]Item, error) {
+	data, err := fetch(ctx, s.url)
+	if err != nil {
+		return nil, err
+	}
+	return decodeItems(data)
+}
+
+// MemorySource serves a fixed list of items. Useful in tests.
+type MemorySource struct {
+	name  string
+	items []Item
+}
+
+func NewMemorySource(name string, items []Item) *MemorySource {
+	return &MemorySource{
+		name:  name,
+		items: items,
+	}
+}
+
+func (s *MemorySource) Name() string {
+	return s.name
+}
+
+func (s *MemorySource) Load(ctx context.Context) ([]Item, error) {
+	return s.items, nil
+}
+
+// ChainSource tries each source in order and returns the first
+// non-empty result.
+type ChainSource struct {
+	sources []ItemSource
+	used    ItemSource
+}
+
+func NewChainSource(sources ...ItemSource) *ChainSource {
+	return &ChainSource{
+		sources: sources,
+	}
+}
+
+// NewSourceChain builds a ChainSource from configuration.
+func NewSourceChain(settings []config.SourceSettings) (*ChainSource, error) {
+	var sources []ItemSource
+	for _, s := range settings {
+		switch s.Type {
+		case config.SourceMetaForge:
+			sources = append(sources, NewMetaForgeSource(s.URL))
+		case config.SourceFile:
+			sources = append(sources, NewFileSource(s.Path))
+		case config.SourceMirror:
+			sources = append(sources, NewMirrorSource(s.URL))
+		case config.SourceEmbedded:
+			sources = append(sources, NewEmbeddedSource(snapshotFile))
+		default:
+			return nil, fmt.Errorf("unknown item source type: %q", s.Type)
+		}
+	}
+	return NewChainSource(sources...), nil
+}
+
+func (c *ChainSource) Name() string {
+	return "chain"
+}
+
+func (c *ChainSource) Load(ctx context.Context) ([]Item, error) {
+	var errs []error
+	for _, source := range c.sources {
+		items, err := source.Load(ctx)
+		if err == nil && len(items) == 0 {
+			err = fmt.Errorf("%w: no items", ErrSourceUnavailable)
+		}
+		if err != nil {
+			slog.Warn("item source failed", "source", source.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+
+		c.used = source
+		slog.Info("items loaded", "source", source.Name(), "count", len(items))
+		return items, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: no sources configured", ErrSourceUnavailable)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Used returns the source that satisfied the last successful Load,
+// or nil if none has.
+func (c *ChainSource) Used() ItemSource {
+	return c.used
+}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrAPIUnavailable, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAPIUnavailable, err)
+	}
+	return data, nil
+}
+
+// decodeItems accepts either a bare item array or an API-style
+// {"data": [...]} envelope.
+func decodeItems(data []byte) ([]Item, error) {
+	var items []Item
+	if err := json.Unmarshal(data, &items); err == nil {
+		return items, nil
+	}
+
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode items: %w", err)
+	}
+	return response.Data, nil
+}
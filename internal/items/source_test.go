@@ -0,0 +1,159 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"arc-scanner/internal/config"
+)
+
+type failingSource struct{}
+
+func (failingSource) Name() string { return "failing" }
+
+func (failingSource) Load(ctx context.Context) ([]Item, error) {
+	return nil, ErrSourceUnavailable
+}
+
+func TestChainSource_FallsBack(t *testing.T) {
+	want := []Item{{ID: "item-1", Name: "Item One", Value: 100}}
+	fallback := NewMemorySource("memory", want)
+
+	chain := NewChainSource(failingSource{}, NewMemorySource("empty", nil), fallback)
+
+	got, err := chain.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "item-1" {
+		t.Errorf("Load returned %v, want %v", got, want)
+	}
+	if chain.Used() != fallback {
+		t.Errorf("Used() = %v, want memory source", chain.Used())
+	}
+}
+
+func TestChainSource_AllFail(t *testing.T) {
+	chain := NewChainSource(failingSource{}, failingSource{})
+
+	_, err := chain.Load(context.Background())
+	if !errors.Is(err, ErrSourceUnavailable) {
+		t.Errorf("Load error = %v, want ErrSourceUnavailable", err)
+	}
+	if chain.Used() != nil {
+		t.Error("Used() should be nil when every source fails")
+	}
+}
+
+func TestFileSource_Formats(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "arc-scanner-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "bare array", content: `[{"id":"item-1","name":"Item One","value":100}]`},
+		{name: "api envelope", content: `{"data":[{"id":"item-1","name":"Item One","value":100}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, "items.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			got, err := NewFileSource(path).Load(context.Background())
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(got) != 1 || got[0].Value != 100 {
+				t.Errorf("Load returned %v, want one item with value 100", got)
+			}
+		})
+	}
+}
+
+func TestMirrorSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"item-1","name":"Item One","value":100}]`))
+	}))
+	defer server.Close()
+
+	got, err := NewMirrorSource(server.URL).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "item-1" {
+		t.Errorf("Load returned %v, want item-1", got)
+	}
+}
+
+func TestMirrorSource_BadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewMirrorSource(server.URL).Load(context.Background())
+	if !errors.Is(err, ErrAPIUnavailable) {
+		t.Errorf("Load error = %v, want ErrAPIUnavailable", err)
+	}
+}
+
+func TestNewSourceChain(t *testing.T) {
+	chain, err := NewSourceChain([]config.SourceSettings{
+		{Type: config.SourceMirror, URL: "http://mirror.local/items.json"},
+		{Type: config.SourceMetaForge},
+	})
+	if err != nil {
+		t.Fatalf("NewSourceChain failed: %v", err)
+	}
+	if len(chain.sources) != 2 {
+		t.Fatalf("chain has %d sources, want 2", len(chain.sources))
+	}
+	if chain.sources[0].Name() != config.SourceMirror {
+		t.Errorf("first source = %s, want %s", chain.sources[0].Name(), config.SourceMirror)
+	}
+
+	if _, err := NewSourceChain([]config.SourceSettings{{Type: "ftp"}}); err == nil {
+		t.Error("NewSourceChain should reject unknown source types")
+	}
+}
+
+func TestEmbeddedSource(t *testing.T) {
+	data := []byte(`{"generatedAt":"2025-01-02T03:04:05Z","items":[{"id":"item-1","name":"Item One","value":100}]}`)
+	source := NewEmbeddedSource(data)
+
+	got, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "item-1" {
+		t.Errorf("Load returned %v, want item-1", got)
+	}
+	if source.GeneratedAt().Year() != 2025 {
+		t.Errorf("GeneratedAt() = %v, want 2025-01-02", source.GeneratedAt())
+	}
+}
+
+func TestEmbeddedSource_Empty(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte(`{"generatedAt":"0001-01-01T00:00:00Z","items":[]}`),
+		[]byte(`{"items":[{"id":"item-1","name":"Item One","value":100}]}`),
+	} {
+		if _, err := NewEmbeddedSource(data).Load(context.Background()); !errors.Is(err, ErrSourceUnavailable) {
+			t.Errorf("Load(%s) error = %v, want ErrSourceUnavailable", data, err)
+		}
+	}
+}
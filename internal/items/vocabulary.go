@@ -0,0 +1,62 @@
+package items
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tesseract user-patterns escapes: \d is a digit, \* repeats the
+// preceding character class.
+const (
+	patternDigits   = `\d\*`
+	patternQuantity = `\d\*/\d\*` // stack counts such as "5/10"
+	patternTier     = `I\*`       // I, II, III
+)
+
+var (
+	// nonWordChars splits names into the words Tesseract can output
+	// with the scanner's whitelist.
+	nonWordChars = regexp.MustCompile(`[^A-Z0-9']+`)
+	digitRuns    = regexp.MustCompile(`[0-9]+`)
+)
+
+// Vocabulary returns the words and patterns Tesseract's dictionary is
+// extended with for items: every word of the names the matcher looks
+// for, the tier suffixes, and patterns for stack counts, for tiers and
+// for name words with numbers in them (e.g. "MK3" -> "MK\d\*"). Both
+// lists are sorted and free of duplicates.
+func Vocabulary(items []Item) (words, patterns []string) {
+	wordSet := make(map[string]bool)
+	patternSet := map[string]bool{patternQuantity: true, patternTier: true}
+
+	for _, suffix := range romanSuffixes {
+		wordSet[suffix] = true
+	}
+
+	for _, item := range items {
+		names := append(searchNames(item), strings.ToUpper(item.Name))
+		for _, name := range names {
+			for _, word := range nonWordChars.Split(name, -1) {
+				if word == "" {
+					continue
+				}
+				wordSet[word] = true
+				if digitRuns.MatchString(word) {
+					patternSet[digitRuns.ReplaceAllString(word, patternDigits)] = true
+				}
+			}
+		}
+	}
+
+	return sortedKeys(wordSet), sortedKeys(patternSet)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,35 @@
+package items
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVocabulary(t *testing.T) {
+	words, patterns := Vocabulary([]Item{
+		{ID: "arc-alloy", Name: "ARC Alloy"},
+		{ID: "anvil-ii", Name: "Anvil II"},
+		{ID: "wolfpack-mk3", Name: "Wolfpack Mk.3", Aliases: []string{"Wolf Pack"}},
+		{ID: "blueprint-recipe", Name: "Blueprint"},
+	})
+
+	for _, want := range []string{"ALLOY", "ANVIL", "ARC", "BLUEPRINT", "II", "III", "IV", "MK", "MK3", "PACK", "WOLF", "WOLFPACK"} {
+		if !slices.Contains(words, want) {
+			t.Errorf("words = %v, missing %s", words, want)
+		}
+	}
+	// Stripped from the search name, and "." isn't in the whitelist
+	for _, unwanted := range []string{"RECIPE", "MK.3", ""} {
+		if slices.Contains(words, unwanted) {
+			t.Errorf("words = %v, want no %q", words, unwanted)
+		}
+	}
+	if !slices.IsSorted(words) || len(slices.Compact(slices.Clone(words))) != len(words) {
+		t.Errorf("words = %v, want sorted and unique", words)
+	}
+
+	wantPatterns := []string{`I\*`, `MK\d\*`, `\d\*`, `\d\*/\d\*`}
+	if !slices.Equal(patterns, wantPatterns) {
+		t.Errorf("patterns = %v, want %v", patterns, wantPatterns)
+	}
+}
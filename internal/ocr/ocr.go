@@ -0,0 +1,83 @@
+package ocr
+
+import (
+	"image"
+	"strings"
+)
+
+// Result is what Tesseract read from an image: the plain text, kept
+// for callers that only need a string, and the layout it came from.
+type Result struct {
+	Text       string
+	Confidence float64 // mean word confidence, 0-100
+	Blocks     []Block
+}
+
+// Block is a region of text, e.g. the tooltip title or description.
+type Block struct {
+	Box   image.Rectangle
+	Lines []Line
+}
+
+// Line is one row of words. Paragraph numbers the paragraph within the
+// block, so blank lines can be told apart from plain line breaks.
+type Line struct {
+	Paragraph int
+	Box       image.Rectangle
+	Words     []Word
+}
+
+// Word is a single recognized word. Boxes are in pixels of the image
+// OCR ran on.
+type Word struct {
+	Text       string
+	Box        image.Rectangle
+	Confidence float64 // 0-100
+}
+
+// Lines returns every line of the result in reading order.
+func (r Result) Lines() []Line {
+	var lines []Line
+	for _, block := range r.Blocks {
+		lines = append(lines, block.Lines...)
+	}
+	return lines
+}
+
+// Words returns every word of the result in reading order.
+func (r Result) Words() []Word {
+	var words []Word
+	for _, line := range r.Lines() {
+		words = append(words, line.Words...)
+	}
+	return words
+}
+
+// Text joins the line's words with spaces.
+func (l Line) Text() string {
+	texts := make([]string, len(l.Words))
+	for i, word := range l.Words {
+		texts[i] = word.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// Confidence is the mean confidence of the line's words.
+func (l Line) Confidence() float64 {
+	confidences := make([]float64, len(l.Words))
+	for i, word := range l.Words {
+		confidences[i] = word.Confidence
+	}
+	return mean(confidences)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
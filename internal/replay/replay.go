@@ -0,0 +1,304 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+// LabelsFile lists the samples of a replay directory.
+const LabelsFile = "labels.json"
+
+// NoItem is reported as the prediction when nothing was matched.
+const NoItem = "(none)"
+
+// Sample is a labeled screenshot.
+type Sample struct {
+	Image    string `json:"image"`    // relative to the labels file
+	ItemID   string `json:"itemId"`   // empty when no item should be found
+	Quantity int    `json:"quantity"` // zero skips the quantity check
+}
+
+// Identifier is the part of the scanner that is replayed: everything
+// after the screen capture.
+type Identifier interface {
+	Identify(ctx context.Context, img image.Image, score scanner.Scorer) (scanner.ScanResult, error)
+}
+
+// Options make a replay take the same steps as the app.
+type Options struct {
+	// TooltipDetection is scanner.tooltipDetection: captures of the
+	// detection area are cut up like a live scan's when it is on.
+	TooltipDetection bool
+
+	// Identify names the item in a scan, e.g. identify.Identifier's.
+	Identify func(result scanner.ScanResult) (items.Item, string, error)
+}
+
+// Result is the outcome of one sample.
+type Result struct {
+	Image            string             `json:"image"`
+	Expected         string             `json:"expected"`
+	Predicted        string             `json:"predicted"`
+	ExpectedQuantity int                `json:"expectedQuantity,omitempty"`
+	Quantity         int                `json:"quantity,omitempty"`
+	Variant          string             `json:"variant,omitempty"`
+	Source           string             `json:"source,omitempty"` // what identified the item
+	Confidence       float64            `json:"confidence"`
+	Correct          bool               `json:"correct"`
+	Error            string             `json:"error,omitempty"`
+	TimingsMs        map[string]float64 `json:"timingsMs"`
+}
+
+// Confusion counts how often one item was read as another.
+type Confusion struct {
+	Expected  string `json:"expected"`
+	Predicted string `json:"predicted"`
+	Count     int    `json:"count"`
+}
+
+// Latency summarizes one stage's timings in milliseconds.
+type Latency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	Max  float64 `json:"max"`
+}
+
+// Report is the outcome of a replay run.
+type Report struct {
+	Samples        int `json:"samples"`
+	TruePositives  int `json:"truePositives"`
+	FalsePositives int `json:"falsePositives"`
+	FalseNegatives int `json:"falseNegatives"`
+	TrueNegatives  int `json:"trueNegatives"`
+
+	Precision        float64 `json:"precision"`
+	Recall           float64 `json:"recall"`
+	QuantityAccuracy float64 `json:"quantityAccuracy"`
+
+	Confusions []Confusion        `json:"confusions"`
+	Latency    map[string]Latency `json:"latencyMs"`
+	Results    []Result           `json:"results"`
+}
+
+// LoadSamples reads the labels file of dir and resolves image paths.
+func LoadSamples(dir string) ([]Sample, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LabelsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	var samples []Sample
+	if err := json.Unmarshal(data, &samples); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+
+	for i := range samples {
+		if samples[i].Image == "" {
+			return nil, fmt.Errorf("sample %d has no image", i)
+		}
+		if !filepath.IsAbs(samples[i].Image) {
+			samples[i].Image = filepath.Join(dir, samples[i].Image)
+		}
+	}
+	return samples, nil
+}
+
+// Run replays every sample through the same tooltip detection,
+// preprocessing, OCR and identification as a live scan. The matcher
+// scores the reads of the preprocessing variants. Cancelling ctx fails
+// the remaining samples.
+func Run(ctx context.Context, samples []Sample, identifier Identifier, matcher *items.Matcher, opts Options) Report {
+	report := Report{
+		Samples: len(samples),
+		Results: make([]Result, 0, len(samples)),
+	}
+
+	for _, sample := range samples {
+		report.Results = append(report.Results, replay(ctx, sample, identifier, matcher, opts))
+	}
+
+	report.summarize()
+	return report
+}
+
+func replay(ctx context.Context, sample Sample, identifier Identifier, matcher *items.Matcher, opts Options) Result {
+	result := Result{
+		Image:            sample.Image,
+		Expected:         sample.ItemID,
+		Predicted:        NoItem,
+		ExpectedQuantity: sample.Quantity,
+		TimingsMs:        make(map[string]float64),
+	}
+	if result.Expected == "" {
+		result.Expected = NoItem
+	}
+
+	img, err := loadImage(sample.Image)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// The map is shared with the returned copy, so this lands in it
+	begin := time.Now()
+	defer func() {
+		result.TimingsMs["total"] = milliseconds(time.Since(begin))
+	}()
+
+	// With tooltip detection on, full detection-area captures are cut up
+	// like in the live app; anything else is taken as the capture a scan
+	// reads, without a slot to match icons against
+	start := time.Now()
+	var slot image.Image
+	if opts.TooltipDetection && img.Bounds().Dx() == config.DetectBoxWidth && img.Bounds().Dy() == config.DetectBoxHeight {
+		img, slot, _ = scanner.SplitCapture(img)
+	}
+	result.TimingsMs["detect"] = milliseconds(time.Since(start))
+
+	scan, err := identifier.Identify(ctx, img, matcher.Score)
+	result.TimingsMs["preprocess"] = milliseconds(scan.Timings.Preprocess)
+	result.TimingsMs["ocr"] = milliseconds(scan.Timings.OCR)
+	result.TimingsMs["adaptive"] = milliseconds(scan.Timings.Adaptive)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Variant, result.Confidence = scan.Variant, scan.Confidence
+
+	scan.Slot = slot
+
+	start = time.Now()
+	item, source, err := opts.Identify(scan)
+	result.TimingsMs["match"] = milliseconds(time.Since(start))
+	if err == nil {
+		result.Predicted, result.Source = item.ID, source
+		result.Quantity = items.ParseQuantityWords(scan.OCRResult)
+	}
+
+	result.Correct = result.Predicted == result.Expected
+	return result
+}
+
+func (r *Report) summarize() {
+	confusions := make(map[[2]string]int)
+	stages := make(map[string][]float64)
+	quantityChecked, quantityCorrect := 0, 0
+
+	for _, result := range r.Results {
+		expectItem := result.Expected != NoItem
+		foundItem := result.Predicted != NoItem
+
+		switch {
+		case result.Correct && expectItem:
+			r.TruePositives++
+		case result.Correct:
+			r.TrueNegatives++
+		default:
+			if foundItem {
+				r.FalsePositives++
+			}
+			if expectItem {
+				r.FalseNegatives++
+			}
+			confusions[[2]string{result.Expected, result.Predicted}]++
+		}
+
+		if result.Correct && expectItem && result.ExpectedQuantity > 0 {
+			quantityChecked++
+			if result.Quantity == result.ExpectedQuantity {
+				quantityCorrect++
+			}
+		}
+
+		for stage, ms := range result.TimingsMs {
+			stages[stage] = append(stages[stage], ms)
+		}
+	}
+
+	r.Precision = ratio(r.TruePositives, r.TruePositives+r.FalsePositives)
+	r.Recall = ratio(r.TruePositives, r.TruePositives+r.FalseNegatives)
+	r.QuantityAccuracy = ratio(quantityCorrect, quantityChecked)
+
+	r.Confusions = make([]Confusion, 0, len(confusions))
+	for pair, count := range confusions {
+		r.Confusions = append(r.Confusions, Confusion{Expected: pair[0], Predicted: pair[1], Count: count})
+	}
+	sort.Slice(r.Confusions, func(i, j int) bool {
+		a, b := r.Confusions[i], r.Confusions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Expected != b.Expected {
+			return a.Expected < b.Expected
+		}
+		return a.Predicted < b.Predicted
+	})
+
+	r.Latency = make(map[string]Latency, len(stages))
+	for stage, values := range stages {
+		r.Latency[stage] = summarizeLatency(values)
+	}
+}
+
+func summarizeLatency(values []float64) Latency {
+	if len(values) == 0 {
+		return Latency{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	percentile := func(p float64) float64 {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+
+	return Latency{
+		Mean: sum / float64(len(sorted)),
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
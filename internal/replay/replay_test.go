@@ -0,0 +1,188 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/identify"
+	"arc-scanner/internal/items"
+	"arc-scanner/internal/scanner"
+)
+
+var testItems = []items.Item{
+	{ID: "arc-alloy", Name: "ARC Alloy"},
+	{ID: "combat-knife-ii", Name: "Combat Knife II"},
+}
+
+// options identifies items like the app without icon matching.
+func options(matcher *items.Matcher, itemsList []items.Item) Options {
+	return Options{Identify: identify.New(matcher, nil, items.BuildIndex(itemsList)).Identify}
+}
+
+// fakeIdentifier "reads" the text encoded in a sample's red channel.
+type fakeIdentifier struct {
+	texts map[uint8]string
+}
+
+func (f fakeIdentifier) Identify(ctx context.Context, img image.Image, score scanner.Scorer) (scanner.ScanResult, error) {
+	r, _, _, _ := img.At(0, 0).RGBA()
+	text, ok := f.texts[uint8(r>>8)]
+	if !ok {
+		return scanner.ScanResult{}, errors.New("OCR failed")
+	}
+	return scanner.ScanResult{
+		OCRResult: scanner.OCRResult{Text: text, Confidence: 90},
+		Variant:   scanner.DefaultVariant,
+	}, nil
+}
+
+func writeSample(t *testing.T, dir, name string, marker uint8) {
+	t.Helper()
+	writeSampleSized(t, dir, name, marker, 20, 20)
+}
+
+func writeSampleSized(t *testing.T, dir, name string, marker uint8, width, height int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.NRGBA{marker, 0, 0, 255})
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSamples(t *testing.T) {
+	dir := t.TempDir()
+	labels := `[{"image": "a.png", "itemId": "arc-alloy", "quantity": 3}, {"image": "/abs/b.png"}]`
+	if err := os.WriteFile(filepath.Join(dir, LabelsFile), []byte(labels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := LoadSamples(dir)
+	if err != nil {
+		t.Fatalf("LoadSamples failed: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(samples))
+	}
+	if samples[0].Image != filepath.Join(dir, "a.png") || samples[0].ItemID != "arc-alloy" || samples[0].Quantity != 3 {
+		t.Errorf("sample 0 = %+v", samples[0])
+	}
+	if samples[1].Image != "/abs/b.png" {
+		t.Errorf("sample 1 image = %s, want absolute path kept", samples[1].Image)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeSample(t, dir, "alloy.png", 1)
+	writeSample(t, dir, "alloy-misread.png", 2)
+	writeSample(t, dir, "knife.png", 3)
+	writeSample(t, dir, "empty.png", 4)
+	writeSample(t, dir, "broken.png", 5)
+
+	identifier := fakeIdentifier{texts: map[uint8]string{
+		1: "ARC ALLOY\n3/10",
+		2: "COMBAT KNIFE II", // wrong item
+		3: "C0MBAT KN1FE",    // unreadable
+		4: "INVENTORY",
+	}}
+	matcher := items.NewMatcher(testItems)
+
+	samples := []Sample{
+		{Image: filepath.Join(dir, "alloy.png"), ItemID: "arc-alloy", Quantity: 3},
+		{Image: filepath.Join(dir, "alloy-misread.png"), ItemID: "arc-alloy"},
+		{Image: filepath.Join(dir, "knife.png"), ItemID: "combat-knife-ii"},
+		{Image: filepath.Join(dir, "empty.png")},
+		{Image: filepath.Join(dir, "broken.png"), ItemID: "arc-alloy"},
+	}
+
+	report := Run(context.Background(), samples, identifier, matcher, options(matcher, testItems))
+
+	if report.TruePositives != 1 || report.FalsePositives != 1 || report.FalseNegatives != 3 || report.TrueNegatives != 1 {
+		t.Errorf("TP/FP/FN/TN = %d/%d/%d/%d, want 1/1/3/1",
+			report.TruePositives, report.FalsePositives, report.FalseNegatives, report.TrueNegatives)
+	}
+	if report.Precision != 0.5 {
+		t.Errorf("Precision = %v, want 0.5", report.Precision)
+	}
+	if report.Recall != 0.25 {
+		t.Errorf("Recall = %v, want 0.25", report.Recall)
+	}
+	if report.QuantityAccuracy != 1 {
+		t.Errorf("QuantityAccuracy = %v, want 1", report.QuantityAccuracy)
+	}
+
+	want := []Confusion{
+		{Expected: "arc-alloy", Predicted: NoItem, Count: 1},
+		{Expected: "arc-alloy", Predicted: "combat-knife-ii", Count: 1},
+		{Expected: "combat-knife-ii", Predicted: NoItem, Count: 1},
+	}
+	if len(report.Confusions) != len(want) {
+		t.Fatalf("Confusions = %+v, want %+v", report.Confusions, want)
+	}
+	for i := range want {
+		if report.Confusions[i] != want[i] {
+			t.Errorf("Confusions[%d] = %+v, want %+v", i, report.Confusions[i], want[i])
+		}
+	}
+
+	if report.Results[4].Error == "" {
+		t.Error("broken sample has no error")
+	}
+	for _, stage := range []string{"detect", "ocr", "match", "total"} {
+		if _, ok := report.Latency[stage]; !ok {
+			t.Errorf("no latency for stage %s", stage)
+		}
+	}
+}
+
+func TestRun_TooltipDetection(t *testing.T) {
+	dir := t.TempDir()
+	// A detection-area capture whose marker is lost when it is cropped
+	writeSampleSized(t, dir, "wide.png", 1, config.DetectBoxWidth, config.DetectBoxHeight)
+
+	identifier := fakeIdentifier{texts: map[uint8]string{1: "ARC ALLOY"}}
+	matcher := items.NewMatcher(testItems)
+	samples := []Sample{{Image: filepath.Join(dir, "wide.png"), ItemID: "arc-alloy"}}
+
+	tests := []struct {
+		name      string
+		detection bool
+		want      string
+	}{
+		{name: "detection off reads the capture whole", detection: false, want: "arc-alloy"},
+		{name: "detection on crops it", detection: true, want: NoItem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options(matcher, testItems)
+			opts.TooltipDetection = tt.detection
+
+			report := Run(context.Background(), samples, identifier, matcher, opts)
+			if got := report.Results[0].Predicted; got != tt.want {
+				t.Errorf("Predicted = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeLatency(t *testing.T) {
+	l := summarizeLatency([]float64{5, 1, 3, 2, 4})
+	if l.Mean != 3 || l.P50 != 3 || l.Max != 5 || l.P95 != 5 {
+		t.Errorf("summarizeLatency = %+v", l)
+	}
+}
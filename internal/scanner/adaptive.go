@@ -0,0 +1,227 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DefaultVariant names the configured preprocessing pipeline in scan
+// results.
+const DefaultVariant = "default"
+
+// Scorer rates an OCR read. It returns a key for what was recognized
+// (e.g. an item ID), a score between 0 and 1 that accounts for how
+// confidently the relevant words were read, and false if nothing was.
+type Scorer func(result OCRResult) (key string, score float64, ok bool)
+
+// ScanResult is the winning read of a scan.
+type ScanResult struct {
+	OCRResult
+	Variant    string  // preprocessing variant that produced the read
+	Score      float64 // combined vote for the winning key, 0-1
+	Candidates int     // number of variants that were read in time
+	Cached     bool    // the read was reused from an earlier scan of the same tooltip
+
+	// Display is the bounds of the display the scan happened on, in
+	// capture coordinates. Set by Scan only.
+	Display image.Rectangle
+
+	// Raw is the whole capture in reference pixels and Tooltip the
+	// detected tooltip within it, empty when none was found. Set by Scan
+	// only, also when it fails after capturing.
+	Raw     image.Image
+	Tooltip image.Rectangle
+
+	Capture   image.Image // part of Raw that was read, set by Scan only
+	Slot      image.Image // hovered inventory slot in reference pixels, set by Scan only
+	Processed image.Image // Capture after the winning variant's preprocessing
+	Timings   Timings
+}
+
+// Timings breaks down where a scan spent its time. Preprocess and OCR
+// are those of the winning variant; Adaptive covers all variant reads.
+type Timings struct {
+	Capture    time.Duration
+	Preprocess time.Duration
+	OCR        time.Duration
+	Adaptive   time.Duration
+}
+
+// variant is a named preprocessing pipeline.
+type variant struct {
+	name     string
+	pipeline *Pipeline
+}
+
+// candidate is one variant's read of the capture.
+type candidate struct {
+	variant   string
+	result    OCRResult
+	key       string
+	score     float64
+	ok        bool
+	processed image.Image
+	timings   Timings
+}
+
+// weight is the candidate's 0-1 vote.
+func (c candidate) weight() float64 {
+	if !c.ok {
+		return 0
+	}
+	return c.score
+}
+
+func (c candidate) scanResult(candidates int) ScanResult {
+	return ScanResult{
+		OCRResult:  c.result,
+		Variant:    c.variant,
+		Score:      c.weight(),
+		Candidates: candidates,
+		Processed:  c.processed,
+		Timings:    c.timings,
+	}
+}
+
+// Identify preprocesses and reads an already captured image. When the
+// first pass scores below the adaptive threshold, every variant is read
+// concurrently within the time budget and the results vote. A read
+// without any text fails with ErrEmptyOCR.
+func (s *TesseractScanner) Identify(ctx context.Context, img image.Image, score Scorer) (ScanResult, error) {
+	primary, err := s.read(ctx, variant{name: DefaultVariant, pipeline: s.pipeline}, img, score)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	if len(s.variants) == 0 || s.adaptiveThreshold <= 0 || primary.weight() >= s.adaptiveThreshold {
+		return checkEmpty(primary.scanResult(1))
+	}
+
+	start := time.Now()
+	candidates := append([]candidate{primary}, s.readVariants(ctx, img, score)...)
+	if err := ctx.Err(); err != nil {
+		return ScanResult{}, fmt.Errorf("adaptive OCR stopped: %w", err)
+	}
+	result := vote(candidates)
+	result.Timings.Adaptive = time.Since(start)
+
+	slog.Debug("adaptive OCR",
+		"firstPass", primary.weight(),
+		"variant", result.Variant,
+		"score", result.Score,
+		"candidates", result.Candidates,
+		"duration", result.Timings.Adaptive)
+
+	return checkEmpty(result)
+}
+
+// checkEmpty fails a result whose read has no text. The result is kept
+// for debug captures.
+func checkEmpty(result ScanResult) (ScanResult, error) {
+	if strings.TrimSpace(result.Text) == "" {
+		return result, ErrEmptyOCR
+	}
+	return result, nil
+}
+
+func (s *TesseractScanner) read(ctx context.Context, v variant, img image.Image, score Scorer) (candidate, error) {
+	start := time.Now()
+	processed, err := v.pipeline.Apply(ctx, img)
+	if err != nil {
+		return candidate{}, fmt.Errorf("preprocessing stopped: %w", err)
+	}
+	preprocessed := time.Now()
+
+	result, err := s.engine.Recognize(ctx, processed)
+	if err != nil {
+		return candidate{}, err
+	}
+
+	c := candidate{
+		variant:   v.name,
+		result:    result,
+		processed: processed,
+		timings: Timings{
+			Preprocess: preprocessed.Sub(start),
+			OCR:        time.Since(preprocessed),
+		},
+	}
+	c.key, c.score, c.ok = score(result)
+	return c, nil
+}
+
+// readVariants reads the image with every variant in parallel and
+// returns the candidates that finished within the budget. Reads still
+// running when it expires are cancelled.
+func (s *TesseractScanner) readVariants(ctx context.Context, img image.Image, score Scorer) []candidate {
+	type read struct {
+		candidate candidate
+		err       error
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.adaptiveBudget)
+	defer cancel()
+
+	reads := make(chan read, len(s.variants))
+	for _, v := range s.variants {
+		go func(v variant) {
+			c, err := s.read(ctx, v, img, score)
+			reads <- read{candidate: c, err: err}
+		}(v)
+	}
+
+	var candidates []candidate
+	for range s.variants {
+		select {
+		case r := <-reads:
+			if r.err != nil {
+				slog.Debug("OCR variant failed", "error", r.err)
+				continue
+			}
+			candidates = append(candidates, r.candidate)
+		case <-ctx.Done():
+			slog.Debug("OCR variant budget exceeded",
+				"finished", len(candidates),
+				"variants", len(s.variants),
+				"budget", s.adaptiveBudget)
+			return candidates
+		}
+	}
+	return candidates
+}
+
+// vote sums the weights of candidates that agree on the same key and
+// returns the strongest candidate of the key with the highest total. If
+// no candidate matched anything, the first one is returned unscored.
+func vote(candidates []candidate) ScanResult {
+	totals := make(map[string]float64)
+	best := make(map[string]candidate)
+	for _, c := range candidates {
+		if !c.ok {
+			continue
+		}
+		totals[c.key] += c.weight()
+		if b, ok := best[c.key]; !ok || c.weight() > b.weight() {
+			best[c.key] = c
+		}
+	}
+
+	if len(totals) == 0 {
+		return candidates[0].scanResult(len(candidates))
+	}
+
+	var winner string
+	for key, total := range totals {
+		if winner == "" || total > totals[winner] || (total == totals[winner] && key < winner) {
+			winner = key
+		}
+	}
+
+	result := best[winner].scanResult(len(candidates))
+	result.Score = totals[winner] / float64(len(candidates))
+	return result
+}
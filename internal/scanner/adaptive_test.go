@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// funcEngine answers every Recognize call with fn.
+type funcEngine struct {
+	fn    func(img image.Image) (OCRResult, error)
+	calls atomic.Int32
+}
+
+func (e *funcEngine) Name() string { return "func" }
+
+func (e *funcEngine) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	e.calls.Add(1)
+	return e.fn(img)
+}
+
+func (e *funcEngine) Close() error { return nil }
+
+// itemScorer recognizes ARC ALLOY, scoring it lower when glued to noise
+// or read with low confidence.
+func itemScorer(result OCRResult) (string, float64, bool) {
+	conf := result.Confidence / 100
+	switch {
+	case strings.Contains(" "+result.Text+" ", " ARC ALLOY "):
+		return "arc-alloy", conf, true
+	case strings.Contains(result.Text, "ARC ALLOY"):
+		return "arc-alloy", 0.6 * conf, true
+	}
+	return "", 0, false
+}
+
+func mustPipeline(t *testing.T, stages ...config.StageSettings) *Pipeline {
+	t.Helper()
+	p, err := NewPipeline(stages)
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+	return p
+}
+
+// newAdaptiveScanner reads white images poorly and black ones cleanly.
+// The default pipeline leaves the white test image alone; the "invert"
+// variant turns it black.
+func newAdaptiveScanner(t *testing.T, engine OCREngine) *TesseractScanner {
+	return &TesseractScanner{
+		engine:            engine,
+		pipeline:          mustPipeline(t),
+		variants:          []variant{{name: "invert", pipeline: mustPipeline(t, config.StageSettings{Stage: "invert"})}},
+		adaptiveThreshold: 0.6,
+		adaptiveBudget:    time.Second,
+	}
+}
+
+func readByColor(img image.Image) (OCRResult, error) {
+	if r, _, _, _ := img.At(0, 0).RGBA(); r > 0 {
+		return OCRResult{Text: "XARC ALLOYS", Confidence: 40}, nil
+	}
+	return OCRResult{Text: "ARC ALLOY", Confidence: 95}, nil
+}
+
+func TestIdentify_ConfidentFirstPass(t *testing.T) {
+	engine := &funcEngine{fn: readByColor}
+	s := newAdaptiveScanner(t, engine)
+
+	result, err := s.Identify(context.Background(), imaging.New(10, 10, color.Black), itemScorer)
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	if result.Variant != DefaultVariant || result.Candidates != 1 {
+		t.Errorf("result = %+v, want default variant only", result)
+	}
+	if engine.calls.Load() != 1 {
+		t.Errorf("engine called %d times, want 1", engine.calls.Load())
+	}
+}
+
+func TestIdentify_VariantWins(t *testing.T) {
+	s := newAdaptiveScanner(t, &funcEngine{fn: readByColor})
+
+	result, err := s.Identify(context.Background(), imaging.New(10, 10, color.White), itemScorer)
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	if result.Variant != "invert" || result.Text != "ARC ALLOY" || result.Confidence != 95 {
+		t.Errorf("result = %+v, want the invert variant's clean read", result)
+	}
+	if result.Candidates != 2 {
+		t.Errorf("Candidates = %d, want 2", result.Candidates)
+	}
+}
+
+func TestIdentify_Budget(t *testing.T) {
+	s := newAdaptiveScanner(t, &funcEngine{fn: func(img image.Image) (OCRResult, error) {
+		if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+			time.Sleep(200 * time.Millisecond) // the variant is slow
+		}
+		return readByColor(img)
+	}})
+	s.adaptiveBudget = 20 * time.Millisecond
+
+	start := time.Now()
+	result, err := s.Identify(context.Background(), imaging.New(10, 10, color.White), itemScorer)
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Identify took %s, want it bounded by the budget", elapsed)
+	}
+	if result.Variant != DefaultVariant || result.Candidates != 1 {
+		t.Errorf("result = %+v, want the first pass", result)
+	}
+}
+
+func TestVote(t *testing.T) {
+	tests := []struct {
+		name        string
+		candidates  []candidate
+		wantVariant string
+	}{
+		{
+			name: "agreement beats a single confident read",
+			candidates: []candidate{
+				{variant: "default", result: OCRResult{Text: "A", Confidence: 95}, key: "a", score: 0.95, ok: true},
+				{variant: "otsu", result: OCRResult{Text: "B", Confidence: 70}, key: "b", score: 0.7, ok: true},
+				{variant: "adaptive", result: OCRResult{Text: "B", Confidence: 75}, key: "b", score: 0.75, ok: true},
+			},
+			wantVariant: "adaptive",
+		},
+		{
+			name: "match score weighs in",
+			candidates: []candidate{
+				{variant: "default", result: OCRResult{Confidence: 90}, key: "a", score: 0.54, ok: true},
+				{variant: "otsu", result: OCRResult{Confidence: 80}, key: "b", score: 0.8, ok: true},
+			},
+			wantVariant: "otsu",
+		},
+		{
+			name: "unmatched reads don't vote",
+			candidates: []candidate{
+				{variant: "default", result: OCRResult{Confidence: 99}},
+				{variant: "otsu", result: OCRResult{Confidence: 30}, key: "a", score: 0.3, ok: true},
+			},
+			wantVariant: "otsu",
+		},
+		{
+			name: "nothing matched",
+			candidates: []candidate{
+				{variant: "default", result: OCRResult{Text: "noise"}},
+				{variant: "otsu"},
+			},
+			wantVariant: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vote(tt.candidates); got.Variant != tt.wantVariant {
+				t.Errorf("vote = %+v, want variant %s", got, tt.wantVariant)
+			}
+		})
+	}
+}
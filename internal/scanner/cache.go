@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+// Tooltips share a layout and differ mostly in a few glyphs, such as a
+// tier suffix, so unlike an icon hash the tooltip hash keeps one bit per
+// tooltipHashCell square of reference pixels, about a quarter of a glyph.
+// Neighbors closer than tooltipHashMargin gray levels count as equal, so
+// flat panel background doesn't flip bits on noise.
+const (
+	tooltipHashCell   = 3
+	tooltipHashMargin = 16
+)
+
+// tooltipHash is a dHash of a capture: whether each cell of a thumbnail
+// is clearly brighter than its right neighbor, plus the capture size.
+type tooltipHash struct {
+	size image.Point
+	bits []uint64
+}
+
+func newTooltipHash(img image.Image) tooltipHash {
+	size := img.Bounds().Size()
+	cols := max(size.X/tooltipHashCell, 1)
+	rows := max(size.Y/tooltipHashCell, 1)
+	gray := imaging.Resize(imaging.Grayscale(img), cols+1, rows, imaging.Box)
+
+	h := tooltipHash{
+		size: size,
+		bits: make([]uint64, (cols*rows+63)/64),
+	}
+	bit := 0
+	for y := 0; y < rows; y++ {
+		row := gray.Pix[y*gray.Stride:]
+		for x := 0; x < cols; x++ {
+			if int(row[x*4])-int(row[(x+1)*4]) > tooltipHashMargin {
+				h.bits[bit/64] |= 1 << (bit % 64)
+			}
+			bit++
+		}
+	}
+	return h
+}
+
+// distance is the number of differing bits. Captures of different sizes,
+// i.e. different tooltips, never match.
+func (h tooltipHash) distance(other tooltipHash) int {
+	if h.size != other.size {
+		return math.MaxInt
+	}
+	d := 0
+	for i := range h.bits {
+		d += bits.OnesCount64(h.bits[i] ^ other.bits[i])
+	}
+	return d
+}
+
+// resultCache remembers the last scan results by the hash of their
+// capture, most recently used first. It is small, so lookups scan it.
+type resultCache struct {
+	size      int
+	tolerance int // bits two hashes may differ by and still match
+
+	mu      sync.Mutex
+	entries []cacheEntry
+}
+
+type cacheEntry struct {
+	hash   tooltipHash
+	result ScanResult
+}
+
+func newResultCache(size, tolerance int) *resultCache {
+	return &resultCache{
+		size:      size,
+		tolerance: tolerance,
+	}
+}
+
+// get returns the cached result of the closest matching capture.
+func (c *resultCache) get(hash tooltipHash) (ScanResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	best, bestDistance := -1, c.tolerance+1
+	for i, e := range c.entries {
+		if d := e.hash.distance(hash); d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 {
+		return ScanResult{}, false
+	}
+
+	entry := c.entries[best]
+	copy(c.entries[1:best+1], c.entries[:best])
+	c.entries[0] = entry
+	return entry.result, true
+}
+
+// put stores a result, replacing an entry for the same capture and
+// evicting the least recently used one when full.
+func (c *resultCache) put(hash tooltipHash, result ScanResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, e := range c.entries {
+		if e.hash.distance(hash) <= c.tolerance {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+
+	c.entries = append([]cacheEntry{{hash: hash, result: result}}, c.entries...)
+	if len(c.entries) > c.size {
+		c.entries = c.entries[:c.size]
+	}
+}
+
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// textTooltip draws a tooltip panel with one line of text per entry,
+// scaled up to roughly the size of a real one.
+func textTooltip(lines ...string) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 180, 110))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{28, 30, 34, 255}), image.Point{}, draw.Src)
+
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.NRGBA{235, 235, 230, 255}),
+		Face: basicfont.Face7x13,
+	}
+	for i, line := range lines {
+		d.Dot = fixed.P(10, 20+i*18)
+		d.DrawString(line)
+	}
+	return imaging.Resize(img, 360, 220, imaging.Linear)
+}
+
+// jitter shifts a channel by up to 8 levels, like capture noise.
+func jitter(v uint8) uint8 {
+	shift := int(v)*31%17 - 8
+	return uint8(min(max(int(v)+shift, 0), 255))
+}
+
+func TestTooltipHash_Distance(t *testing.T) {
+	base := textTooltip("Anvil II", "Hand Cannon", "Weight 5.0", "Value 5000")
+
+	tests := []struct {
+		name  string
+		img   image.Image
+		match bool
+	}{
+		{"same capture", textTooltip("Anvil II", "Hand Cannon", "Weight 5.0", "Value 5000"), true},
+		{"brighter", imaging.AdjustBrightness(base, 6), true},
+		{"noise", imaging.AdjustFunc(base, func(c color.NRGBA) color.NRGBA {
+			c.R, c.G, c.B = jitter(c.R), jitter(c.G), jitter(c.B)
+			return c
+		}), true},
+		{"other tier", textTooltip("Anvil III", "Hand Cannon", "Weight 5.0", "Value 5000"), false},
+		{"other item", textTooltip("Rattler I", "Assault Rifle", "Weight 6.0", "Value 3000"), false},
+		{"other size", imaging.Resize(base, 360, 240, imaging.Linear), false},
+	}
+
+	const tolerance = 6
+	hash := newTooltipHash(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := hash.distance(newTooltipHash(tt.img))
+			if got := d <= tolerance; got != tt.match {
+				t.Errorf("distance = %d, match = %v, want %v", d, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	hashes := make([]tooltipHash, 3)
+	for i, name := range []string{"Anvil II", "Rattler I", "Ferro III"} {
+		hashes[i] = newTooltipHash(textTooltip(name, "Value 5000"))
+	}
+	result := func(text string) ScanResult {
+		return ScanResult{OCRResult: OCRResult{Text: text}}
+	}
+
+	cache := newResultCache(2, 6)
+	if _, ok := cache.get(hashes[0]); ok {
+		t.Fatal("empty cache returned a result")
+	}
+
+	cache.put(hashes[0], result("anvil"))
+	cache.put(hashes[1], result("rattler"))
+	if got, ok := cache.get(hashes[0]); !ok || got.Text != "anvil" {
+		t.Fatalf("get = %q, %v, want anvil", got.Text, ok)
+	}
+
+	// The rattler is now least recently used and makes room
+	cache.put(hashes[2], result("ferro"))
+	if _, ok := cache.get(hashes[1]); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if got, ok := cache.get(hashes[0]); !ok || got.Text != "anvil" {
+		t.Errorf("get = %q, %v, want anvil", got.Text, ok)
+	}
+
+	// Putting the same capture again replaces its read
+	cache.put(hashes[2], result("ferro ii"))
+	if got, _ := cache.get(hashes[2]); got.Text != "ferro ii" {
+		t.Errorf("get = %q, want replaced read", got.Text)
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("entries = %d, want 2", len(cache.entries))
+	}
+
+	cache.clear()
+	if _, ok := cache.get(hashes[0]); ok {
+		t.Error("cleared cache returned a result")
+	}
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package scanner
+
+import (
+	"errors"
+	"image"
+	"os"
+	"testing"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// paintRoot draws straight onto the X root window, which shows as is on
+// a server without a window manager or compositor, like Xvfb.
+type paintRoot struct {
+	conn   *xgb.Conn
+	screen *xproto.ScreenInfo
+	gc     xproto.Gcontext
+}
+
+// requireX11 connects to $DISPLAY, run the test under
+//
+//	xvfb-run -s "-screen 0 1920x1080x24" go test ./internal/scanner -run X11
+func requireX11(t *testing.T) *paintRoot {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" || os.Getenv("XDG_SESSION_TYPE") == "wayland" {
+		t.Skip("needs an X11 display, run under xvfb-run")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Skipf("no X11 connection: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	gc, err := xproto.NewGcontextId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateGC(conn, gc, xproto.Drawable(screen.Root), 0, nil)
+	return &paintRoot{conn: conn, screen: screen, gc: gc}
+}
+
+func (p *paintRoot) fill(t *testing.T, r image.Rectangle, white bool) {
+	t.Helper()
+	pixel := p.screen.BlackPixel
+	if white {
+		pixel = p.screen.WhitePixel
+	}
+	xproto.ChangeGC(p.conn, p.gc, xproto.GcForeground, []uint32{pixel})
+	xproto.PolyFillRectangle(p.conn, xproto.Drawable(p.screen.Root), p.gc, []xproto.Rectangle{{
+		X: int16(r.Min.X), Y: int16(r.Min.Y), Width: uint16(r.Dx()), Height: uint16(r.Dy()),
+	}})
+	// A round trip makes sure the server drew it before the capture
+	if _, err := xproto.GetInputFocus(p.conn).Reply(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCapture_X11(t *testing.T) {
+	root := requireX11(t)
+
+	s := &TesseractScanner{}
+	g, err := s.geometry()
+	if err != nil {
+		t.Fatalf("geometry failed: %v", err)
+	}
+	display := g.Displays[0]
+	if g.UIScale(display) != 1 {
+		t.Skipf("display %v is not 1080p", display)
+	}
+
+	box := image.Rect(0, 0, 200, 100)
+	cursor := display.Min.Add(image.Pt(400, 300))
+	place := g.Place(cursor, box)
+
+	root.fill(t, place.Full, false)
+	root.fill(t, image.Rectangle{Min: place.Full.Min, Max: place.Full.Min.Add(image.Pt(100, 100))}, true)
+
+	img, err := captureNormalized(place)
+	if err != nil {
+		t.Fatalf("captureNormalized failed: %v", err)
+	}
+	if img.Bounds().Size() != box.Size() {
+		t.Fatalf("capture size = %v, want %v", img.Bounds().Size(), box.Size())
+	}
+	if r, _, _, _ := img.At(50, 50).RGBA(); r>>8 < 200 {
+		t.Errorf("painted half = %d, want white", r>>8)
+	}
+	if r, _, _, _ := img.At(150, 50).RGBA(); r>>8 > 50 {
+		t.Errorf("unpainted half = %d, want black", r>>8)
+	}
+
+	// A uniform capture may be a loading screen, so it isn't taken as denied
+	root.fill(t, place.Full, false)
+	if _, err := captureNormalized(place); !errors.Is(err, ErrBlankCapture) {
+		t.Errorf("blank capture err = %v, want ErrBlankCapture", err)
+	}
+}
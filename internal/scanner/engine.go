@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"time"
+
+	"arc-scanner/internal/config"
+)
+
+// ErrEngineUnavailable is returned when an OCR engine can't be used in
+// this build or on this machine.
+var ErrEngineUnavailable = errors.New("OCR engine unavailable")
+
+// childCPU is the CPU time used by tesseract processes that have exited.
+var childCPU atomic.Int64
+
+// ChildCPUTime returns the CPU time used so far by the tesseract
+// processes OCR started, for auto-scan's CPU cap.
+func ChildCPUTime() time.Duration {
+	return time.Duration(childCPU.Load())
+}
+
+// OCREngine turns a preprocessed image into text.
+type OCREngine interface {
+	Name() string
+	Recognize(ctx context.Context, img image.Image) (OCRResult, error)
+	Close() error
+}
+
+// newEngine picks the OCR engine named in settings. "auto" prefers the
+// in-process libtesseract engine and falls back to spawning the CLI.
+// vocab may be nil.
+func newEngine(name, tesseractPath, tessdataPath string, vocab *vocabulary) OCREngine {
+	switch name {
+	case config.OCREngineExec:
+		return newExecEngine(tesseractPath, tessdataPath, vocab)
+	case config.OCREngineLib, config.OCREngineAuto, "":
+		engine, err := NewLibEngine(tessdataPath)
+		if err == nil {
+			engine.useVocabulary(vocab)
+			return engine
+		}
+		if name == config.OCREngineLib {
+			slog.Warn("libtesseract engine unavailable, using tesseract CLI", "error", err)
+		}
+		return newExecEngine(tesseractPath, tessdataPath, vocab)
+	default:
+		slog.Warn("unknown OCR engine, using tesseract CLI", "engine", name)
+		return newExecEngine(tesseractPath, tessdataPath, vocab)
+	}
+}
+
+// ExecEngine runs the tesseract binary once per image.
+type ExecEngine struct {
+	tesseractPath string
+	tessdataPath  string
+	vocab         *vocabulary
+}
+
+func NewExecEngine(tesseractPath, tessdataPath string) *ExecEngine {
+	return &ExecEngine{
+		tesseractPath: tesseractPath,
+		tessdataPath:  tessdataPath,
+	}
+}
+
+func newExecEngine(tesseractPath, tessdataPath string, vocab *vocabulary) *ExecEngine {
+	engine := NewExecEngine(tesseractPath, tessdataPath)
+	engine.vocab = vocab
+	return engine
+}
+
+func (e *ExecEngine) Name() string {
+	return config.OCREngineExec
+}
+
+func (e *ExecEngine) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return OCRResult{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	args := []string{
+		"stdin",  // Read from stdin
+		"stdout", // Output to stdout
+		"-l", config.TesseractLang,
+		"--psm", config.TesseractPSM,
+		"--oem", config.TesseractOEM,
+		"-c", "tessedit_char_whitelist=" + config.TesseractWhitelist,
+	}
+	args = append(args, e.vocab.args()...)
+	args = append(args, "tsv") // Word-level output with confidences
+
+	cmd := exec.CommandContext(ctx, e.tesseractPath, args...)
+
+	// Don't wait for pipes held open by anything the process started
+	// once it has been killed
+	cmd.WaitDelay = time.Second
+
+	// Hide console window on Windows
+	hideConsoleWindow(cmd)
+
+	// Set tessdata location if using bundled version
+	if e.tessdataPath != "" {
+		cmd.Env = append(os.Environ(), "TESSDATA_PREFIX="+e.tessdataPath)
+	}
+
+	cmd.Stdin = &buf
+
+	output, err := cmd.Output()
+	if state := cmd.ProcessState; state != nil {
+		childCPU.Add(int64(state.UserTime() + state.SystemTime()))
+	}
+	if err != nil {
+		// The process was killed because the scan was cancelled or ran
+		// out of time
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return OCRResult{}, fmt.Errorf("OCR failed: %w", ctxErr)
+		}
+		var stderr string
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = string(exitErr.Stderr)
+		}
+		if typed := classifyExecError(err, stderr); typed != nil {
+			return OCRResult{}, fmt.Errorf("%w: %s: %v %s", typed, e.tesseractPath, err, stderr)
+		}
+		if stderr != "" {
+			return OCRResult{}, fmt.Errorf("OCR failed: %s (stderr: %s)", err, stderr)
+		}
+		return OCRResult{}, fmt.Errorf("OCR failed: %w", err)
+	}
+
+	return parseTSV(string(output)), nil
+}
+
+func (e *ExecEngine) Close() error {
+	return nil
+}
@@ -0,0 +1,138 @@
+//go:build gosseract
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"strconv"
+	"sync"
+
+	"arc-scanner/internal/config"
+	"arc-scanner/internal/ocr"
+
+	"github.com/otiai10/gosseract/v2"
+)
+
+// LibEngine keeps a libtesseract client loaded for the lifetime of the
+// app, so the LSTM model is only read once.
+type LibEngine struct {
+	mu     sync.Mutex
+	client *gosseract.Client
+
+	vocab        *vocabulary
+	vocabVersion int // version of the vocabulary the client was set up with
+}
+
+func NewLibEngine(tessdataPath string) (*LibEngine, error) {
+	client := gosseract.NewClient()
+
+	if tessdataPath != "" {
+		if err := client.SetTessdataPrefix(tessdataPath); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("%w: %v", ErrEngineUnavailable, err)
+		}
+	}
+
+	if err := client.SetLanguage(config.TesseractLang); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: %v", ErrEngineUnavailable, err)
+	}
+
+	psm, _ := strconv.Atoi(config.TesseractPSM)
+	if err := client.SetPageSegMode(gosseract.PageSegMode(psm)); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: %v", ErrEngineUnavailable, err)
+	}
+	if err := client.SetWhitelist(config.TesseractWhitelist); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: %v", ErrEngineUnavailable, err)
+	}
+
+	return &LibEngine{
+		client: client,
+	}, nil
+}
+
+// useVocabulary makes the client pick up the vocabulary, reinitializing
+// it whenever the files are rewritten.
+func (e *LibEngine) useVocabulary(vocab *vocabulary) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.vocab = vocab
+}
+
+// syncVocabulary reinitializes the client if the vocabulary changed
+// since it was loaded. The caller holds e.mu.
+func (e *LibEngine) syncVocabulary() error {
+	path, version := e.vocab.configFile()
+	if path == "" || version == e.vocabVersion {
+		return nil
+	}
+	if err := e.client.SetConfigFile(path); err != nil {
+		return fmt.Errorf("failed to load vocabulary: %w", err)
+	}
+	e.vocabVersion = version
+	return nil
+}
+
+func (e *LibEngine) Name() string {
+	return config.OCREngineLib
+}
+
+// Recognize can't interrupt libtesseract once it runs, so ctx is only
+// checked before starting and after waiting for the client.
+func (e *LibEngine) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return OCRResult{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return OCRResult{}, fmt.Errorf("OCR failed: %w", err)
+	}
+
+	if err := e.syncVocabulary(); err != nil {
+		return OCRResult{}, err
+	}
+
+	if err := e.client.SetImageFromBytes(buf.Bytes()); err != nil {
+		return OCRResult{}, libError(err)
+	}
+
+	words, err := e.client.GetBoundingBoxesVerbose()
+	if err != nil {
+		return OCRResult{}, libError(err)
+	}
+
+	var b layoutBuilder
+	for _, word := range words {
+		b.add(word.BlockNum, word.ParNum, word.LineNum, ocr.Word{
+			Text:       word.Word,
+			Box:        word.Box,
+			Confidence: word.Confidence,
+		})
+	}
+	return b.result(), nil
+}
+
+// libError marks failures to load the language data, which libtesseract
+// only reports once the client is first used.
+func libError(err error) error {
+	if isTessdataError(err.Error()) {
+		return fmt.Errorf("%w: %v", ErrTessdataMissing, err)
+	}
+	return fmt.Errorf("OCR failed: %w", err)
+}
+
+func (e *LibEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.client.Close()
+}
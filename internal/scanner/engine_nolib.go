@@ -0,0 +1,33 @@
+//go:build !gosseract
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"arc-scanner/internal/config"
+)
+
+// LibEngine is only available in builds tagged "gosseract", which link
+// against libtesseract.
+type LibEngine struct{}
+
+func NewLibEngine(tessdataPath string) (*LibEngine, error) {
+	return nil, fmt.Errorf("%w: built without the gosseract tag", ErrEngineUnavailable)
+}
+
+func (e *LibEngine) useVocabulary(vocab *vocabulary) {}
+
+func (e *LibEngine) Name() string {
+	return config.OCREngineLib
+}
+
+func (e *LibEngine) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	return OCRResult{}, ErrEngineUnavailable
+}
+
+func (e *LibEngine) Close() error {
+	return nil
+}
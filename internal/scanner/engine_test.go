@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// loadFixture reads a PNG from testdata.
+func loadFixture(tb testing.TB, name string) image.Image {
+	tb.Helper()
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		tb.Fatalf("failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		tb.Fatalf("failed to decode fixture: %v", err)
+	}
+	return img
+}
+
+// tooltipImage returns the tooltip fixture cropped and preprocessed the
+// way the live scanner does it.
+func tooltipImage(tb testing.TB) image.Image {
+	tb.Helper()
+	img := imaging.Crop(loadFixture(tb, "tooltip_right.png"), staticBox())
+
+	processed := imaging.Grayscale(img)
+	processed = imaging.Invert(processed)
+	processed = imaging.AdjustContrast(processed, config.ContrastLevel)
+	return imaging.Sharpen(processed, config.SharpenLevel)
+}
+
+func requireTesseract(tb testing.TB) string {
+	tb.Helper()
+	path, err := exec.LookPath(tesseractBinaryName())
+	if err != nil {
+		tb.Skip("tesseract not installed")
+	}
+	return path
+}
+
+func TestNewEngine(t *testing.T) {
+	if engine := newEngine(config.OCREngineExec, "tesseract", "", nil); engine.Name() != config.OCREngineExec {
+		t.Errorf("newEngine(exec) = %s, want %s", engine.Name(), config.OCREngineExec)
+	}
+
+	// Whatever "auto" picks, it must be usable
+	engine := newEngine(config.OCREngineAuto, "tesseract", "", nil)
+	if _, err := NewLibEngine(""); err != nil && engine.Name() != config.OCREngineExec {
+		t.Errorf("newEngine(auto) = %s without libtesseract, want %s", engine.Name(), config.OCREngineExec)
+	}
+	engine.Close()
+}
+
+func TestExecEngine_Deadline(t *testing.T) {
+	if isWindows() {
+		t.Skip("uses a shell script as tesseract")
+	}
+	hung := filepath.Join(t.TempDir(), "tesseract")
+	if err := os.WriteFile(hung, []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	engine := NewExecEngine(hung, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := engine.Recognize(ctx, testImage())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Recognize returned after %v, the process was not killed", elapsed)
+	}
+}
+
+func TestExecEngine_Recognize(t *testing.T) {
+	engine := NewExecEngine(requireTesseract(t), "")
+
+	result, err := engine.Recognize(context.Background(), tooltipImage(t))
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if !strings.Contains(result.Text, "ARC ALLOY") {
+		t.Errorf("Recognize = %q, want it to contain ARC ALLOY", result.Text)
+	}
+	if result.Confidence <= 0 {
+		t.Errorf("Confidence = %v, want word confidences", result.Confidence)
+	}
+}
+
+func BenchmarkEngine_Exec(b *testing.B) {
+	engine := NewExecEngine(requireTesseract(b), "")
+	benchmarkEngine(b, engine)
+}
+
+func BenchmarkEngine_Lib(b *testing.B) {
+	engine, err := NewLibEngine("")
+	if err != nil {
+		b.Skip(err)
+	}
+	defer engine.Close()
+	benchmarkEngine(b, engine)
+}
+
+func benchmarkEngine(b *testing.B, engine OCREngine) {
+	img := tooltipImage(b)
+
+	// Warm up so the lib engine's one-time model load isn't counted
+	if _, err := engine.Recognize(context.Background(), img); err != nil {
+		b.Fatalf("Recognize failed: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.Recognize(context.Background(), img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// Errors a scan can fail with that the user can do something about.
+var (
+	ErrTesseractMissing = errors.New("tesseract not found")
+	ErrTessdataMissing  = errors.New("tesseract language data not found")
+	ErrCaptureDenied    = errors.New("screen capture denied")
+	ErrBlankCapture     = errors.New("screen capture is blank")
+	ErrOCRTimeout       = errors.New("OCR timed out")
+	ErrEmptyOCR         = errors.New("OCR found no text")
+)
+
+// tessdataErrors are fragments of tesseract's messages when it can't load
+// its language data.
+var tessdataErrors = []string{
+	"Error opening data file",
+	"Failed loading language",
+	"Could not initialize tesseract",
+}
+
+// classifyExecError tells a missing binary or language data apart from
+// other failures of the tesseract CLI.
+func classifyExecError(err error, stderr string) error {
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return ErrTesseractMissing
+	case isTessdataError(stderr):
+		return ErrTessdataMissing
+	}
+	return nil
+}
+
+func isTessdataError(message string) bool {
+	for _, fragment := range tessdataErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// blankCapture reports whether every pixel of a capture is the same. That
+// is what macOS returns without the Screen Recording permission, but
+// also what a loading screen or a faded-out menu looks like, so only the
+// caller can tell whether capturing was denied.
+func blankCapture(img *image.RGBA) bool {
+	pix := img.Pix
+	if len(pix) < 4 {
+		return true
+	}
+	r, g, b := pix[0], pix[1], pix[2]
+	for i := 4; i+3 < len(pix); i += 4 {
+		if pix[i] != r || pix[i+1] != g || pix[i+2] != b {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func TestClassifyExecError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		stderr string
+		want   error
+	}{
+		{"not in PATH", &exec.Error{Name: "tesseract", Err: exec.ErrNotFound}, "", ErrTesseractMissing},
+		{"bad path", &fs.PathError{Op: "fork/exec", Path: "/opt/tesseract", Err: fs.ErrNotExist}, "", ErrTesseractMissing},
+		{"no traineddata", errors.New("exit status 1"), "Error opening data file /usr/share/tessdata/eng.traineddata\nFailed loading language 'eng'", ErrTessdataMissing},
+		{"other failure", errors.New("exit status 1"), "Error in pixReadMem", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyExecError(tt.err, tt.stderr); got != tt.want {
+				t.Errorf("classifyExecError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecEngine_TypedErrors(t *testing.T) {
+	if isWindows() {
+		t.Skip("uses a shell script as tesseract")
+	}
+
+	noData := filepath.Join(t.TempDir(), "tesseract")
+	script := "#!/bin/sh\necho \"Failed loading language 'eng'\" >&2\nexit 1\n"
+	if err := os.WriteFile(noData, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{filepath.Join(t.TempDir(), "missing", "tesseract"), ErrTesseractMissing},
+		{noData, ErrTessdataMissing},
+	}
+	for _, tt := range tests {
+		_, err := NewExecEngine(tt.path, "").Recognize(context.Background(), testImage())
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Recognize with %s: err = %v, want %v", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestBlankCapture(t *testing.T) {
+	black := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	if !blankCapture(black) {
+		t.Error("black capture not blank")
+	}
+
+	frame := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	frame.Set(39, 29, color.RGBA{1, 0, 0, 255})
+	if blankCapture(frame) {
+		t.Error("capture with one different pixel is blank")
+	}
+}
+
+func TestIdentify_EmptyOCR(t *testing.T) {
+	engine := &funcEngine{fn: func(img image.Image) (OCRResult, error) {
+		return OCRResult{Text: " \n"}, nil
+	}}
+	s := newAdaptiveScanner(t, engine)
+
+	result, err := s.Identify(context.Background(), imaging.New(10, 10, color.White), itemScorer)
+	if !errors.Is(err, ErrEmptyOCR) {
+		t.Errorf("err = %v, want ErrEmptyOCR", err)
+	}
+	if result.Processed == nil {
+		t.Error("empty result dropped its processed image")
+	}
+	if got := engine.calls.Load(); got != 2 {
+		t.Errorf("engine called %d times, want the first pass and one variant", got)
+	}
+}
@@ -0,0 +1,199 @@
+package scanner
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// offscreenFill paints the parts of a capture box that fall outside the
+// display. Mid gray is neither a dark panel nor a light border, so it
+// doesn't confuse tooltip detection.
+var offscreenFill = color.NRGBA{128, 128, 128, 255}
+
+// Geometry handles every coordinate conversion of a scan. Three spaces
+// are involved:
+//
+//   - cursor coordinates, as reported by robotgo
+//   - capture coordinates, as taken by screenshot.CaptureRect (logical
+//     points on macOS, pixels on Windows). They span the whole virtual
+//     desktop, so displays left of or above the main one are negative.
+//   - reference pixels, in which the capture boxes are defined
+//
+// Captured images come back in physical pixels and are resized to
+// reference pixels, so tooltip detection and preprocessing see the same
+// scale on every display.
+type Geometry struct {
+	Displays    []image.Rectangle // capture coordinates, main display first
+	CursorScale float64           // capture coordinates per cursor coordinate
+	uiScale     float64           // user override, zero to derive it
+}
+
+// Placement is a capture box positioned on a display.
+type Placement struct {
+	Display image.Rectangle // display containing the cursor
+	Box     image.Rectangle // reference pixels, relative to the cursor
+	Full    image.Rectangle // Box in capture coordinates
+	Visible image.Rectangle // Full clipped to Display
+	Scale   float64         // capture coordinates per reference pixel
+}
+
+// NewGeometry builds the geometry for a set of displays. cursorSpace is
+// the size of the main display in cursor coordinates, or zero if they
+// match capture coordinates. uiScale overrides the scale derived from
+// the display height.
+func NewGeometry(displays []image.Rectangle, cursorSpace image.Point, uiScale float64) Geometry {
+	g := Geometry{
+		Displays:    displays,
+		CursorScale: 1,
+		uiScale:     uiScale,
+	}
+
+	if len(displays) > 0 && cursorSpace.X > 0 && displays[0].Dx() > 0 {
+		g.CursorScale = float64(displays[0].Dx()) / float64(cursorSpace.X)
+	}
+
+	return g
+}
+
+// CursorToCapture converts a cursor position to capture coordinates.
+func (g Geometry) CursorToCapture(p image.Point) image.Point {
+	if g.CursorScale == 1 {
+		return p
+	}
+	return image.Pt(
+		int(math.Round(float64(p.X)*g.CursorScale)),
+		int(math.Round(float64(p.Y)*g.CursorScale)),
+	)
+}
+
+// CaptureToCursor converts capture coordinates back to a cursor
+// position.
+func (g Geometry) CaptureToCursor(p image.Point) image.Point {
+	if g.CursorScale == 1 {
+		return p
+	}
+	return image.Pt(
+		int(math.Round(float64(p.X)/g.CursorScale)),
+		int(math.Round(float64(p.Y)/g.CursorScale)),
+	)
+}
+
+// DisplayAt returns the display containing p, or the nearest one when p
+// falls in a gap between displays of different sizes.
+func (g Geometry) DisplayAt(p image.Point) image.Rectangle {
+	var nearest image.Rectangle
+	best := math.MaxInt
+	for _, d := range g.Displays {
+		if p.In(d) {
+			return d
+		}
+		dx := max(d.Min.X-p.X, 0, p.X-(d.Max.X-1))
+		dy := max(d.Min.Y-p.Y, 0, p.Y-(d.Max.Y-1))
+		if dist := dx*dx + dy*dy; dist < best {
+			nearest, best = d, dist
+		}
+	}
+	return nearest
+}
+
+// UIScale returns how many capture coordinates one reference pixel spans
+// on display.
+func (g Geometry) UIScale(display image.Rectangle) float64 {
+	if g.uiScale > 0 {
+		return g.uiScale
+	}
+	if display.Dy() <= 0 {
+		return 1
+	}
+	return float64(display.Dy()) / config.ReferenceHeight
+}
+
+// Place positions a box given in reference pixels relative to the cursor
+// on the display under the cursor.
+func (g Geometry) Place(cursor image.Point, box image.Rectangle) Placement {
+	display := g.DisplayAt(cursor)
+	scale := g.UIScale(display)
+
+	at := func(v int) int {
+		return int(math.Round(float64(v) * scale))
+	}
+	full := image.Rect(
+		cursor.X+at(box.Min.X),
+		cursor.Y+at(box.Min.Y),
+		cursor.X+at(box.Max.X),
+		cursor.Y+at(box.Max.Y),
+	)
+
+	return Placement{
+		Display: display,
+		Box:     box,
+		Full:    full,
+		Visible: full.Intersect(display),
+		Scale:   scale,
+	}
+}
+
+// Normalize resizes a capture of the visible part of the box to
+// reference pixels. Parts of the box outside the display are filled so
+// the result always has the box's size.
+func (p Placement) Normalize(img image.Image) image.Image {
+	size := p.Box.Size()
+
+	if p.Visible == p.Full {
+		if img.Bounds().Size() == size {
+			return img
+		}
+		return imaging.Resize(img, size.X, size.Y, imaging.Lanczos)
+	}
+
+	toRef := func(v int) int {
+		return int(math.Round(float64(v) / p.Scale))
+	}
+	visible := image.Rect(
+		toRef(p.Visible.Min.X-p.Full.Min.X),
+		toRef(p.Visible.Min.Y-p.Full.Min.Y),
+		toRef(p.Visible.Max.X-p.Full.Min.X),
+		toRef(p.Visible.Max.Y-p.Full.Min.Y),
+	)
+
+	canvas := imaging.New(size.X, size.Y, offscreenFill)
+	if visible.Empty() {
+		return canvas
+	}
+	resized := imaging.Resize(img, visible.Dx(), visible.Dy(), imaging.Lanczos)
+	return imaging.Paste(canvas, resized, visible.Min)
+}
+
+// ocrBox is the fixed tooltip area relative to the cursor, in reference
+// pixels.
+func ocrBox() image.Rectangle {
+	return image.Rect(
+		config.OcrBoxXOffset,
+		-config.OcrBoxYOffset,
+		config.OcrBoxXOffset+config.OcrBoxWidth,
+		-config.OcrBoxYOffset+config.OcrBoxHeight,
+	)
+}
+
+// detectBox is the wide tooltip detection area relative to the cursor,
+// in reference pixels.
+func detectBox() image.Rectangle {
+	return image.Rect(
+		-config.DetectBoxXOffset,
+		-config.DetectBoxYOffset,
+		-config.DetectBoxXOffset+config.DetectBoxWidth,
+		-config.DetectBoxYOffset+config.DetectBoxHeight,
+	)
+}
+
+// slotBox is the inventory slot centered on the cursor, in reference
+// pixels.
+func slotBox() image.Rectangle {
+	half := config.SlotBoxSize / 2
+	return image.Rect(-half, -half, config.SlotBoxSize-half, config.SlotBoxSize-half)
+}
@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func TestGeometry_UIScale(t *testing.T) {
+	tests := []struct {
+		name    string
+		display image.Rectangle
+		uiScale float64
+		want    float64
+	}{
+		{"1080p", image.Rect(0, 0, 1920, 1080), 0, 1},
+		{"1440p", image.Rect(0, 0, 2560, 1440), 0, 4.0 / 3},
+		{"4K", image.Rect(0, 0, 3840, 2160), 0, 2},
+		{"retina logical points", image.Rect(0, 0, 1512, 982), 0, 982.0 / 1080},
+		{"override", image.Rect(0, 0, 2560, 1440), 1.25, 1.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGeometry([]image.Rectangle{tt.display}, image.Point{}, tt.uiScale)
+			if got := g.UIScale(tt.display); got != tt.want {
+				t.Errorf("UIScale = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometry_CursorToCapture(t *testing.T) {
+	// 4K display at 150% scaling, cursor reported in logical pixels
+	g := NewGeometry([]image.Rectangle{image.Rect(0, 0, 3840, 2160)}, image.Pt(2560, 1440), 0)
+	if g.CursorScale != 1.5 {
+		t.Errorf("CursorScale = %v, want 1.5", g.CursorScale)
+	}
+	if got := g.CursorToCapture(image.Pt(1000, 600)); got != image.Pt(1500, 900) {
+		t.Errorf("CursorToCapture = %v, want (1500,900)", got)
+	}
+
+	same := NewGeometry([]image.Rectangle{image.Rect(0, 0, 1920, 1080)}, image.Pt(1920, 1080), 0)
+	if same.CursorScale != 1 {
+		t.Errorf("CursorScale = %v, want 1", same.CursorScale)
+	}
+}
+
+// Main 1440p display, a 1080p display to its left, and a 4K display
+// above-right, as arranged on a virtual desktop.
+var testDisplays = []image.Rectangle{
+	image.Rect(0, 0, 2560, 1440),
+	image.Rect(-1920, 360, 0, 1440),
+	image.Rect(2560, -720, 6400, 1440),
+}
+
+func TestGeometry_DisplayAt(t *testing.T) {
+	g := NewGeometry(testDisplays, image.Point{}, 0)
+
+	tests := []struct {
+		name   string
+		cursor image.Point
+		want   image.Rectangle
+	}{
+		{"main", image.Pt(100, 100), testDisplays[0]},
+		{"negative x", image.Pt(-500, 800), testDisplays[1]},
+		{"negative y", image.Pt(3000, -100), testDisplays[2]},
+		{"gap above left display", image.Pt(-500, 100), testDisplays[1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.DisplayAt(tt.cursor); got != tt.want {
+				t.Errorf("DisplayAt(%v) = %v, want %v", tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometry_Place(t *testing.T) {
+	g := NewGeometry(testDisplays, image.Point{}, 0)
+	box := ocrBox() // (0,-400)-(450,80) in reference pixels
+
+	tests := []struct {
+		name        string
+		cursor      image.Point
+		wantFull    image.Rectangle
+		wantVisible image.Rectangle
+	}{
+		{
+			name:        "1080p display left of main",
+			cursor:      image.Pt(-1000, 1000),
+			wantFull:    image.Rect(-1000, 600, -550, 1080),
+			wantVisible: image.Rect(-1000, 600, -550, 1080),
+		},
+		{
+			name:        "4K display",
+			cursor:      image.Pt(3000, 0),
+			wantFull:    image.Rect(3000, -800, 3900, 160),
+			wantVisible: image.Rect(3000, -720, 3900, 160),
+		},
+		{
+			name:        "clipped at the edge shared with another display",
+			cursor:      image.Pt(2200, 1000),
+			wantFull:    image.Rect(2200, 467, 2800, 1107),
+			wantVisible: image.Rect(2200, 467, 2560, 1107),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := g.Place(tt.cursor, box)
+			if p.Full != tt.wantFull {
+				t.Errorf("Full = %v, want %v", p.Full, tt.wantFull)
+			}
+			if p.Visible != tt.wantVisible {
+				t.Errorf("Visible = %v, want %v", p.Visible, tt.wantVisible)
+			}
+		})
+	}
+}
+
+func TestPlacement_Normalize(t *testing.T) {
+	g := NewGeometry(testDisplays, image.Point{}, 0)
+	box := ocrBox()
+
+	// A 4K capture is twice the reference size
+	p := g.Place(image.Pt(3000, 500), box)
+	img := imaging.New(p.Visible.Dx(), p.Visible.Dy(), color.White)
+	if got := p.Normalize(img).Bounds().Size(); got != box.Size() {
+		t.Errorf("Normalize size = %v, want %v", got, box.Size())
+	}
+
+	// Clipped captures are padded back to the full box
+	p = g.Place(image.Pt(3000, 0), box)
+	img = imaging.New(p.Visible.Dx(), p.Visible.Dy(), color.White)
+	out := imaging.Clone(p.Normalize(img))
+	if got := out.Bounds().Size(); got != box.Size() {
+		t.Fatalf("Normalize size = %v, want %v", got, box.Size())
+	}
+	if got := out.NRGBAAt(10, 10); got != offscreenFill {
+		t.Errorf("offscreen pixel = %v, want %v", got, offscreenFill)
+	}
+	if got := out.NRGBAAt(10, 100).R; got != 255 {
+		t.Errorf("captured pixel = %d, want 255", got)
+	}
+
+	// Already in reference pixels
+	p = g.Place(image.Pt(-1000, 1000), box)
+	same := imaging.New(450, 480, color.White)
+	if got := p.Normalize(same); got != image.Image(same) {
+		t.Error("Normalize resized an image already in reference pixels")
+	}
+}
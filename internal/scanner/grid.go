@@ -0,0 +1,293 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"github.com/kbinani/screenshot"
+)
+
+// ErrNoGrid is returned when no inventory slot grid is found on screen.
+var ErrNoGrid = errors.New("no inventory grid found")
+
+// Grid detection limits, in reference pixels.
+const (
+	gridMinSlot     = 48  // smallest slot pitch we expect
+	gridMaxSlot     = 140 // largest
+	gridMinLines    = 3   // grid lines needed along each axis
+	gridTolerance   = 3   // how far a line may stray from the pitch
+	gridEdgeMin     = 25  // luminance step that counts as a slot border
+	gridBorderInset = 2   // pixels trimmed off each cell to drop the border
+
+	emptySlotMaxStdDev = 8 // flat slots hold no item
+)
+
+// GridCell is one slot of a detected grid, in reference pixels.
+type GridCell struct {
+	Row, Col int
+	Bounds   image.Rectangle
+}
+
+// GridSlot is an occupied slot of a scanned inventory.
+type GridSlot struct {
+	Row, Col int
+	Image    image.Image // the slot in reference pixels
+	Cursor   image.Point // slot center in cursor coordinates
+	Quantity int         // stack count printed on the slot, 0 if unread
+}
+
+// FindGrid looks for a regular grid of square slots: vertical and
+// horizontal borders repeating at the same pitch. It returns the cells
+// row by row, or false if no grid was found.
+func FindGrid(img image.Image) ([]GridCell, bool) {
+	gray := imaging.Grayscale(img)
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	lum := func(x, y int) int {
+		return int(gray.Pix[y*gray.Stride+x*4])
+	}
+
+	// Count strong luminance steps per column and per row
+	colEdges := make([]float64, w)
+	rowEdges := make([]float64, h)
+	for y := 1; y < h; y++ {
+		for x := 1; x < w; x++ {
+			v := lum(x, y)
+			if abs(v-lum(x-1, y)) >= gridEdgeMin {
+				colEdges[x]++
+			}
+			if abs(v-lum(x, y-1)) >= gridEdgeMin {
+				rowEdges[y]++
+			}
+		}
+	}
+
+	colPeaks, rowPeaks := edgePeaks(colEdges), edgePeaks(rowEdges)
+
+	cols, pitch := longestPitchRun(colPeaks, gridMinSlot, gridMaxSlot)
+	if len(cols) < gridMinLines {
+		return nil, false
+	}
+	// Slots are square, so rows repeat at the same pitch
+	rows, _ := longestPitchRun(rowPeaks, pitch-gridTolerance, pitch+gridTolerance)
+	if len(rows) < gridMinLines {
+		return nil, false
+	}
+
+	colSpans := cellSpans(cols, colPeaks, pitch)
+	rowSpans := cellSpans(rows, rowPeaks, pitch)
+
+	var cells []GridCell
+	for r, rs := range rowSpans {
+		for c, cs := range colSpans {
+			bounds := image.Rect(cs[0], rs[0], cs[1], rs[1]).Inset(gridBorderInset)
+			cells = append(cells, GridCell{Row: r, Col: c, Bounds: bounds})
+		}
+	}
+	return cells, len(cells) > 0
+}
+
+// edgePeaks returns the positions where a profile stands out from its
+// mean, keeping the strongest of neighboring positions: both sides of a
+// border line light up.
+func edgePeaks(profile []float64) []int {
+	if len(profile) == 0 {
+		return nil
+	}
+
+	mean, std := meanStdDev(profile)
+	threshold := mean + 2*std
+
+	var peaks []int
+	for i, v := range profile {
+		if v <= threshold || v == 0 {
+			continue
+		}
+		if n := len(peaks); n > 0 && i-peaks[n-1] <= gridTolerance {
+			if v > profile[peaks[n-1]] {
+				peaks[n-1] = i
+			}
+			continue
+		}
+		peaks = append(peaks, i)
+	}
+	return peaks
+}
+
+// longestPitchRun finds the longest sequence of peaks repeating at a pitch
+// between minPitch and maxPitch. Ties go to the run starting first, so
+// with gaps between slots the left (top) borders win over the right.
+func longestPitchRun(peaks []int, minPitch, maxPitch int) ([]int, int) {
+	var best []int
+	bestPitch := 0
+
+	for i := range peaks {
+		for j := i + 1; j < len(peaks); j++ {
+			pitch := peaks[j] - peaks[i]
+			if pitch < minPitch {
+				continue
+			}
+			if pitch > maxPitch {
+				break
+			}
+
+			run := []int{peaks[i], peaks[j]}
+			for {
+				next, ok := nearestPeak(peaks, run[len(run)-1]+pitch, gridTolerance)
+				if !ok {
+					break
+				}
+				run = append(run, next)
+			}
+
+			if len(run) > len(best) {
+				best = run
+				bestPitch = (run[len(run)-1] - run[0]) / (len(run) - 1)
+			}
+		}
+	}
+	return best, bestPitch
+}
+
+// cellSpans turns the start lines of a run into cells, each ending at
+// the next border found within a pitch. A line with no border after it
+// closes the grid and starts no cell.
+func cellSpans(lines, peaks []int, pitch int) [][2]int {
+	var spans [][2]int
+	for _, start := range lines {
+		for _, p := range peaks {
+			if p >= start+pitch*3/4 && p <= start+pitch+gridTolerance {
+				spans = append(spans, [2]int{start, p})
+				break
+			}
+		}
+	}
+	return spans
+}
+
+func nearestPeak(peaks []int, want, tolerance int) (int, bool) {
+	i := sort.SearchInts(peaks, want-tolerance)
+	if i < len(peaks) && peaks[i] <= want+tolerance {
+		return peaks[i], true
+	}
+	return 0, false
+}
+
+// ScanGrid captures the display under the cursor, finds the inventory
+// grid on it and returns the occupied slots with their stack counts.
+func (s *TesseractScanner) ScanGrid(ctx context.Context, x, y int) ([]GridSlot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("capture stopped: %w", err)
+	}
+	g, err := s.geometry()
+	if err != nil {
+		return nil, err
+	}
+	display := g.DisplayAt(g.CursorToCapture(image.Pt(x, y)))
+
+	captured, err := screenshot.CaptureRect(display)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCaptureDenied, err)
+	}
+	if blankCapture(captured) {
+		return nil, fmt.Errorf("%w: %v", ErrBlankCapture, display)
+	}
+
+	// Grid limits are in reference pixels like the capture boxes
+	scale := g.UIScale(display)
+	ref := imaging.Resize(captured,
+		int(math.Round(float64(display.Dx())/scale)),
+		int(math.Round(float64(display.Dy())/scale)),
+		imaging.Lanczos)
+
+	cells, ok := FindGrid(ref)
+	if !ok {
+		return nil, ErrNoGrid
+	}
+
+	var slots []GridSlot
+	for _, cell := range cells {
+		img := imaging.Crop(ref, cell.Bounds)
+		if emptySlot(img) {
+			continue
+		}
+
+		center := cell.Bounds.Min.Add(cell.Bounds.Size().Div(2))
+		slots = append(slots, GridSlot{
+			Row:   cell.Row,
+			Col:   cell.Col,
+			Image: img,
+			Cursor: g.CaptureToCursor(image.Pt(
+				display.Min.X+int(math.Round(float64(center.X)*scale)),
+				display.Min.Y+int(math.Round(float64(center.Y)*scale)),
+			)),
+		})
+	}
+
+	// The engine bounds how many reads actually run at once
+	var wg sync.WaitGroup
+	for i := range slots {
+		wg.Add(1)
+		go func(slot *GridSlot) {
+			defer wg.Done()
+			slot.Quantity = s.readStackCount(ctx, slot.Image)
+		}(&slots[i])
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("grid scan stopped: %w", err)
+	}
+
+	slog.Debug("grid scanned", "cells", len(cells), "occupied", len(slots), "display", display)
+	return slots, nil
+}
+
+// stackCountPattern matches the first number OCR finds in a slot corner.
+var stackCountPattern = regexp.MustCompile(`\d+`)
+
+// readStackCount reads the stack count printed in the bottom right
+// corner of a slot. It returns 0 if there is none.
+func (s *TesseractScanner) readStackCount(ctx context.Context, slot image.Image) int {
+	b := slot.Bounds()
+	corner := imaging.Crop(slot, image.Rect(b.Min.X+b.Dx()*2/5, b.Min.Y+b.Dy()*3/5, b.Max.X, b.Max.Y))
+	corner = imaging.Invert(imaging.Grayscale(imaging.Resize(corner, corner.Bounds().Dx()*3, 0, imaging.Lanczos)))
+
+	result, err := s.engine.Recognize(ctx, corner)
+	if err != nil {
+		slog.Debug("stack count unreadable", "error", err)
+		return 0
+	}
+	count, _ := strconv.Atoi(stackCountPattern.FindString(result.Text))
+	return count
+}
+
+// emptySlot reports whether a slot is flat, i.e. holds no item.
+func emptySlot(img image.Image) bool {
+	gray := imaging.Grayscale(img)
+	values := make([]float64, 0, len(gray.Pix)/4)
+	for i := 0; i < len(gray.Pix); i += 4 {
+		values = append(values, float64(gray.Pix[i]))
+	}
+	_, std := meanStdDev(values)
+	return std < emptySlotMaxStdDev
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	m := mean(values)
+	if len(values) == 0 {
+		return 0, 0
+	}
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - m) * (v - m)
+	}
+	return m, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+// inventoryImage draws a rows x cols grid of framed slots at origin,
+// with gap pixels between slots, on a dark screen with some other UI.
+// Slots listed in filled hold a bright "item".
+func inventoryImage(origin image.Point, slot, gap, rows, cols int, filled map[[2]int]bool) image.Image {
+	img := imaging.New(1200, 800, color.NRGBA{20, 22, 25, 255})
+	border := color.NRGBA{110, 110, 115, 255}
+	inside := color.NRGBA{35, 36, 40, 255}
+
+	// A panel edge and a button that aren't part of the grid
+	img = imaging.Paste(img, imaging.New(3, 700, border), image.Pt(40, 50))
+	img = imaging.Paste(img, imaging.New(160, 40, color.NRGBA{200, 180, 60, 255}), image.Pt(900, 60))
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			at := origin.Add(image.Pt(c*(slot+gap), r*(slot+gap)))
+			img = imaging.Paste(img, imaging.New(slot, slot, border), at)
+			img = imaging.Paste(img, imaging.New(slot-4, slot-4, inside), at.Add(image.Pt(2, 2)))
+			if filled[[2]int{r, c}] {
+				img = imaging.Paste(img, imaging.New(slot/2, slot/3, color.NRGBA{230, 120, 40, 255}), at.Add(image.Pt(slot/4, slot/3)))
+			}
+		}
+	}
+	return img
+}
+
+func TestFindGrid(t *testing.T) {
+	tests := []struct {
+		name string
+		slot int
+		gap  int
+	}{
+		{"gaps between slots", 72, 6},
+		{"shared borders", 64, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin := image.Pt(200, 150)
+			img := inventoryImage(origin, tt.slot, tt.gap, 4, 6, nil)
+
+			cells, ok := FindGrid(img)
+			if !ok {
+				t.Fatal("FindGrid found no grid")
+			}
+			if len(cells) != 24 {
+				t.Fatalf("got %d cells, want 24", len(cells))
+			}
+
+			// Third slot of the second row
+			pitch := tt.slot + tt.gap
+			want := image.Rect(0, 0, tt.slot, tt.slot).Add(origin).Add(image.Pt(2*pitch, pitch))
+			got := cells[1*6+2]
+			if got.Row != 1 || got.Col != 2 {
+				t.Errorf("cell 8 is at row %d col %d, want 1, 2", got.Row, got.Col)
+			}
+			if !got.Bounds.In(want) || got.Bounds.Dx() < tt.slot-2*gridBorderInset-gridTolerance {
+				t.Errorf("cell bounds = %v, want inside %v", got.Bounds, want)
+			}
+		})
+	}
+}
+
+func TestFindGrid_None(t *testing.T) {
+	img := imaging.New(800, 600, color.NRGBA{20, 22, 25, 255})
+	img = imaging.Paste(img, imaging.New(300, 200, color.NRGBA{100, 100, 100, 255}), image.Pt(100, 100))
+	if cells, ok := FindGrid(img); ok {
+		t.Errorf("FindGrid found %d cells on a screen without a grid", len(cells))
+	}
+}
+
+func TestEmptySlot(t *testing.T) {
+	filled := map[[2]int]bool{{0, 1}: true}
+	img := inventoryImage(image.Pt(100, 100), 72, 6, 1, 2, filled)
+	cells, ok := FindGrid(img)
+	if !ok {
+		// One row is too few for a grid, so crop the slots by hand
+		cells = []GridCell{
+			{Bounds: image.Rect(100, 100, 172, 172).Inset(gridBorderInset)},
+			{Col: 1, Bounds: image.Rect(178, 100, 250, 172).Inset(gridBorderInset)},
+		}
+	}
+
+	if !emptySlot(imaging.Crop(img, cells[0].Bounds)) {
+		t.Error("empty slot reported as occupied")
+	}
+	if emptySlot(imaging.Crop(img, cells[1].Bounds)) {
+		t.Error("occupied slot reported as empty")
+	}
+}
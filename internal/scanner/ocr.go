@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"image"
+	"strings"
+
+	"arc-scanner/internal/ocr"
+)
+
+// OCRResult is what Tesseract read from an image.
+type OCRResult = ocr.Result
+
+// layoutBuilder assembles an OCRResult from words in reading order, as
+// both engines report them.
+type layoutBuilder struct {
+	blocks      []ocr.Block
+	text        strings.Builder
+	confidences []float64
+
+	started               bool
+	block, par, lineIndex int
+}
+
+// add appends a word identified by its block, paragraph and line
+// numbers. A negative confidence marks a word Tesseract didn't rate; it
+// is kept at zero but left out of the mean.
+func (b *layoutBuilder) add(block, par, line int, word ocr.Word) {
+	word.Text = strings.TrimSpace(word.Text)
+	if word.Text == "" {
+		return
+	}
+	if word.Confidence >= 0 {
+		b.confidences = append(b.confidences, word.Confidence)
+	} else {
+		word.Confidence = 0
+	}
+
+	newBlock := !b.started || block != b.block
+	newPar := newBlock || par != b.par
+	newLine := newPar || line != b.lineIndex
+
+	switch {
+	case !b.started:
+	case newPar:
+		b.text.WriteString("\n\n")
+	case newLine:
+		b.text.WriteString("\n")
+	default:
+		b.text.WriteString(" ")
+	}
+	b.text.WriteString(word.Text)
+	b.started, b.block, b.par, b.lineIndex = true, block, par, line
+
+	if newBlock {
+		b.blocks = append(b.blocks, ocr.Block{})
+	}
+	current := &b.blocks[len(b.blocks)-1]
+	if newLine {
+		current.Lines = append(current.Lines, ocr.Line{Paragraph: par})
+	}
+	l := &current.Lines[len(current.Lines)-1]
+
+	l.Words = append(l.Words, word)
+	l.Box = union(l.Box, word.Box)
+	current.Box = union(current.Box, word.Box)
+}
+
+func (b *layoutBuilder) result() OCRResult {
+	return OCRResult{
+		Text:       b.text.String(),
+		Confidence: mean(b.confidences),
+		Blocks:     b.blocks,
+	}
+}
+
+// union is Rectangle.Union, except that an empty word box at the origin
+// doesn't stretch the result.
+func union(a, b image.Rectangle) image.Rectangle {
+	switch {
+	case a.Empty():
+		return b
+	case b.Empty():
+		return a
+	}
+	return a.Union(b)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
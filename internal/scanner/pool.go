@@ -0,0 +1,212 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+const defaultOCRTimeout = 5 * time.Second
+
+var (
+	ErrPoolClosed    = errors.New("OCR pool closed")
+	ErrWorkerCrashed = errors.New("OCR worker crashed")
+)
+
+// Pool spreads OCR work over a fixed number of workers, each owning its
+// own engine, and replaces workers that hang past the deadline or panic.
+// Every worker reads a blank image when it starts: libtesseract loads
+// its model, and the tesseract CLI pages its binary and traineddata into
+// the OS cache, so the first scan doesn't pay for a cold start. The CLI
+// still starts a process per image; the deadline kills the process of a
+// hung job.
+type Pool struct {
+	newEngine func() OCREngine
+	timeout   time.Duration
+
+	jobs      chan *job
+	done      chan struct{}
+	closeOnce sync.Once
+	nextID    atomic.Int64
+}
+
+type job struct {
+	ctx    context.Context
+	img    image.Image
+	result chan jobResult
+	worker atomic.Pointer[worker]
+}
+
+type jobResult struct {
+	result OCRResult
+	err    error
+}
+
+type worker struct {
+	id      int64
+	engine  OCREngine
+	retired atomic.Bool
+}
+
+// NewPool starts size workers. Each call to Recognize must finish
+// within timeout or the worker running it is replaced.
+func NewPool(size int, timeout time.Duration, newEngine func() OCREngine) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	if timeout <= 0 {
+		timeout = defaultOCRTimeout
+	}
+
+	p := &Pool{
+		newEngine: newEngine,
+		timeout:   timeout,
+		jobs:      make(chan *job),
+		done:      make(chan struct{}),
+	}
+
+	for i := 0; i < size; i++ {
+		p.startWorker()
+	}
+
+	slog.Info("OCR pool started", "workers", size, "timeout", timeout)
+	return p
+}
+
+func (p *Pool) Name() string {
+	return "pool"
+}
+
+// Recognize runs OCR on the next free worker. The engine gets a context
+// that ends with ctx or the pool timeout, whichever comes first; only the
+// latter counts as a hung worker.
+func (p *Pool) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	jobCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	j := &job{
+		ctx:    jobCtx,
+		img:    img,
+		result: make(chan jobResult, 1),
+	}
+
+	select {
+	case p.jobs <- j:
+	case <-jobCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return OCRResult{}, fmt.Errorf("OCR failed: %w", err)
+		}
+		return OCRResult{}, fmt.Errorf("%w: no free worker after %s", ErrOCRTimeout, p.timeout)
+	case <-p.done:
+		return OCRResult{}, ErrPoolClosed
+	}
+
+	select {
+	case r := <-j.result:
+		return r.result, r.err
+	case <-jobCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return OCRResult{}, fmt.Errorf("OCR failed: %w", err)
+		}
+		if w := j.worker.Load(); w != nil {
+			p.retire(w, "hung")
+		}
+		return OCRResult{}, fmt.Errorf("%w: after %s", ErrOCRTimeout, p.timeout)
+	case <-p.done:
+		return OCRResult{}, ErrPoolClosed
+	}
+}
+
+// Close stops all workers and releases their engines. Jobs still
+// running finish in the background.
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	return nil
+}
+
+func (p *Pool) startWorker() {
+	w := &worker{
+		id:     p.nextID.Add(1),
+		engine: p.newEngine(),
+	}
+	go p.run(w)
+}
+
+// retire marks w to exit after its current job and starts a
+// replacement. Safe to call more than once for the same worker.
+func (p *Pool) retire(w *worker, reason string) {
+	if !w.retired.CompareAndSwap(false, true) {
+		return
+	}
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	slog.Warn("replacing OCR worker", "worker", w.id, "reason", reason)
+	p.startWorker()
+}
+
+func (p *Pool) run(w *worker) {
+	defer w.engine.Close()
+
+	p.warmUp(w)
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case j := <-p.jobs:
+			j.worker.Store(w)
+
+			result, err := p.recognize(j.ctx, w, j.img)
+			j.result <- jobResult{result: result, err: err}
+
+			if errors.Is(err, ErrWorkerCrashed) {
+				p.retire(w, "crashed")
+			}
+			if w.retired.Load() {
+				return
+			}
+		}
+	}
+}
+
+// recognize calls the engine, turning a panic into ErrWorkerCrashed.
+func (p *Pool) recognize(ctx context.Context, w *worker, img image.Image) (result OCRResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrWorkerCrashed, r)
+		}
+	}()
+	return w.engine.Recognize(ctx, img)
+}
+
+// warmUpImage is the blank image workers read when they start.
+var warmUpImage = imaging.New(64, 32, color.White)
+
+// warmUp runs the worker's engine once so the first real scan doesn't
+// pay for loading the model or starting tesseract cold.
+func (p *Pool) warmUp(w *worker) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	if _, err := p.recognize(ctx, w, warmUpImage); err != nil {
+		slog.Warn("OCR worker warm-up failed", "worker", w.id, "error", err)
+		return
+	}
+	slog.Debug("OCR worker warmed up", "worker", w.id, "duration", time.Since(start))
+}
@@ -0,0 +1,181 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"image"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeEngine calls fn for every image except the pool's warm-up image,
+// which it only counts.
+type fakeEngine struct {
+	fn      func(img image.Image) (string, error)
+	warmups *atomic.Int32
+	closed  *atomic.Int32
+}
+
+func (e *fakeEngine) Name() string { return "fake" }
+
+func (e *fakeEngine) Recognize(ctx context.Context, img image.Image) (OCRResult, error) {
+	if img == warmUpImage {
+		e.warmups.Add(1)
+		return OCRResult{}, nil
+	}
+	text, err := e.fn(img)
+	return OCRResult{Text: text, Confidence: 90}, err
+}
+
+func (e *fakeEngine) Close() error {
+	e.closed.Add(1)
+	return nil
+}
+
+type fakeEngines struct {
+	created atomic.Int32
+	warmups atomic.Int32
+	closed  atomic.Int32
+}
+
+func (f *fakeEngines) factory(fn func(img image.Image) (string, error)) func() OCREngine {
+	return func() OCREngine {
+		f.created.Add(1)
+		return &fakeEngine{fn: fn, warmups: &f.warmups, closed: &f.closed}
+	}
+}
+
+func testImage() image.Image {
+	return image.NewGray(image.Rect(0, 0, 10, 10))
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPool_Recognize(t *testing.T) {
+	var engines fakeEngines
+	pool := NewPool(3, time.Second, engines.factory(func(img image.Image) (string, error) {
+		return "ARC ALLOY", nil
+	}))
+	defer pool.Close()
+
+	result, err := pool.Recognize(context.Background(), testImage())
+	if err != nil || result.Text != "ARC ALLOY" {
+		t.Fatalf("Recognize = %q, %v, want ARC ALLOY", result.Text, err)
+	}
+
+	waitFor(t, func() bool { return engines.warmups.Load() == 3 })
+	if engines.created.Load() != 3 {
+		t.Errorf("created %d engines, want 3", engines.created.Load())
+	}
+}
+
+func TestPool_ReplacesHungWorker(t *testing.T) {
+	var engines fakeEngines
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+
+	pool := NewPool(1, 50*time.Millisecond, engines.factory(func(img image.Image) (string, error) {
+		if calls.Add(1) == 1 {
+			<-release // first real job hangs
+		}
+		return "ok", nil
+	}))
+	defer pool.Close()
+
+	if _, err := pool.Recognize(context.Background(), testImage()); !errors.Is(err, ErrOCRTimeout) {
+		t.Fatalf("Recognize error = %v, want ErrOCRTimeout", err)
+	}
+
+	// The replacement worker serves the next job while the first hangs
+	result, err := pool.Recognize(context.Background(), testImage())
+	if err != nil || result.Text != "ok" {
+		t.Fatalf("Recognize after timeout = %q, %v, want ok", result.Text, err)
+	}
+	if engines.created.Load() != 2 {
+		t.Errorf("created %d engines, want 2", engines.created.Load())
+	}
+}
+
+func TestPool_Cancelled(t *testing.T) {
+	var engines fakeEngines
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	pool := NewPool(1, time.Second, engines.factory(func(img image.Image) (string, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return "ok", nil
+	}))
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := pool.Recognize(ctx, testImage()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Recognize error = %v, want context.Canceled", err)
+	}
+	close(release)
+
+	// A cancelled scan is not the worker's fault, so it is kept
+	result, err := pool.Recognize(context.Background(), testImage())
+	if err != nil || result.Text != "ok" {
+		t.Fatalf("Recognize after cancel = %q, %v, want ok", result.Text, err)
+	}
+	if engines.created.Load() != 1 {
+		t.Errorf("created %d engines, want 1", engines.created.Load())
+	}
+}
+
+func TestPool_ReplacesCrashedWorker(t *testing.T) {
+	var engines fakeEngines
+	var calls atomic.Int32
+
+	pool := NewPool(1, time.Second, engines.factory(func(img image.Image) (string, error) {
+		if calls.Add(1) == 1 {
+			panic("segfault in tesseract")
+		}
+		return "ok", nil
+	}))
+	defer pool.Close()
+
+	if _, err := pool.Recognize(context.Background(), testImage()); !errors.Is(err, ErrWorkerCrashed) {
+		t.Fatalf("Recognize error = %v, want ErrWorkerCrashed", err)
+	}
+
+	result, err := pool.Recognize(context.Background(), testImage())
+	if err != nil || result.Text != "ok" {
+		t.Fatalf("Recognize after crash = %q, %v, want ok", result.Text, err)
+	}
+	waitFor(t, func() bool { return engines.closed.Load() == 1 })
+}
+
+func TestPool_Close(t *testing.T) {
+	var engines fakeEngines
+	pool := NewPool(2, time.Second, engines.factory(func(img image.Image) (string, error) {
+		return "", nil
+	}))
+
+	pool.Close()
+	pool.Close()
+
+	if _, err := pool.Recognize(context.Background(), testImage()); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Recognize after Close error = %v, want ErrPoolClosed", err)
+	}
+	waitFor(t, func() bool { return engines.closed.Load() == 2 })
+}
@@ -0,0 +1,320 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// Stage is a single preprocessing step.
+type Stage func(img image.Image) *image.NRGBA
+
+// Pipeline is an ordered list of preprocessing stages built from
+// settings.
+type Pipeline struct {
+	names  []string
+	stages []Stage
+}
+
+// PreviewStep is the output of one pipeline stage.
+type PreviewStep struct {
+	Stage string
+	Image image.Image
+}
+
+// stageBuilders maps stage names used in settings to constructors.
+var stageBuilders = map[string]func(s config.StageSettings) (Stage, error){
+	"grayscale": func(s config.StageSettings) (Stage, error) {
+		return func(img image.Image) *image.NRGBA { return imaging.Grayscale(img) }, nil
+	},
+	"invert": func(s config.StageSettings) (Stage, error) {
+		return func(img image.Image) *image.NRGBA { return imaging.Invert(img) }, nil
+	},
+	"contrast": func(s config.StageSettings) (Stage, error) {
+		amount := s.Param("amount", config.ContrastLevel)
+		return func(img image.Image) *image.NRGBA { return imaging.AdjustContrast(img, amount) }, nil
+	},
+	"sharpen": func(s config.StageSettings) (Stage, error) {
+		sigma := s.Param("sigma", config.SharpenLevel)
+		return func(img image.Image) *image.NRGBA { return imaging.Sharpen(img, sigma) }, nil
+	},
+	"gamma": func(s config.StageSettings) (Stage, error) {
+		gamma := s.Param("gamma", 1)
+		if gamma <= 0 {
+			return nil, fmt.Errorf("gamma must be positive, got %v", gamma)
+		}
+		return func(img image.Image) *image.NRGBA { return imaging.AdjustGamma(img, gamma) }, nil
+	},
+	"upscale": func(s config.StageSettings) (Stage, error) {
+		factor := s.Param("factor", 2)
+		if factor <= 0 {
+			return nil, fmt.Errorf("upscale factor must be positive, got %v", factor)
+		}
+		return func(img image.Image) *image.NRGBA {
+			b := img.Bounds()
+			w := int(math.Round(float64(b.Dx()) * factor))
+			h := int(math.Round(float64(b.Dy()) * factor))
+			return imaging.Resize(img, w, h, imaging.Lanczos)
+		}, nil
+	},
+	"binarize": func(s config.StageSettings) (Stage, error) {
+		switch s.Mode {
+		case "", "otsu":
+			return binarizeOtsu, nil
+		case "adaptive":
+			radius := int(s.Param("radius", 15))
+			offset := s.Param("offset", 10)
+			return func(img image.Image) *image.NRGBA { return binarizeAdaptive(img, radius, offset) }, nil
+		default:
+			return nil, fmt.Errorf("unknown binarize mode %q", s.Mode)
+		}
+	},
+	"denoise": func(s config.StageSettings) (Stage, error) {
+		radius := int(s.Param("radius", 1))
+		return func(img image.Image) *image.NRGBA { return medianFilter(img, radius) }, nil
+	},
+	"crop": func(s config.StageSettings) (Stage, error) {
+		left, top := int(s.Param("left", 0)), int(s.Param("top", 0))
+		right, bottom := int(s.Param("right", 0)), int(s.Param("bottom", 0))
+		return func(img image.Image) *image.NRGBA {
+			b := img.Bounds()
+			if left+right >= b.Dx() || top+bottom >= b.Dy() {
+				return imaging.Clone(img)
+			}
+			return imaging.Crop(img, image.Rect(b.Min.X+left, b.Min.Y+top, b.Max.X-right, b.Max.Y-bottom))
+		}, nil
+	},
+	"pad": func(s config.StageSettings) (Stage, error) {
+		size := int(s.Param("size", 10))
+		level := uint8(s.Param("color", 255))
+		return func(img image.Image) *image.NRGBA {
+			b := img.Bounds()
+			bg := imaging.New(b.Dx()+2*size, b.Dy()+2*size, color.NRGBA{level, level, level, 255})
+			return imaging.Paste(bg, img, image.Pt(size, size))
+		}, nil
+	},
+	"channel": func(s config.StageSettings) (Stage, error) {
+		var pick func(r, g, b uint8) uint8
+		switch s.Mode {
+		case "red":
+			pick = func(r, g, b uint8) uint8 { return r }
+		case "green":
+			pick = func(r, g, b uint8) uint8 { return g }
+		case "blue":
+			pick = func(r, g, b uint8) uint8 { return b }
+		case "max":
+			pick = func(r, g, b uint8) uint8 { return max(r, g, b) }
+		default:
+			return nil, fmt.Errorf("unknown channel %q", s.Mode)
+		}
+		return func(img image.Image) *image.NRGBA { return isolateChannel(img, pick) }, nil
+	},
+}
+
+// NewPipeline builds a pipeline from settings, rejecting unknown stages
+// and invalid parameters.
+func NewPipeline(settings []config.StageSettings) (*Pipeline, error) {
+	p := &Pipeline{}
+	for i, s := range settings {
+		build, ok := stageBuilders[s.Stage]
+		if !ok {
+			return nil, fmt.Errorf("stage %d: unknown preprocessing stage %q", i, s.Stage)
+		}
+		stage, err := build(s)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d (%s): %w", i, s.Stage, err)
+		}
+		p.names = append(p.names, s.Stage)
+		p.stages = append(p.stages, stage)
+	}
+	return p, nil
+}
+
+// Apply runs every stage in order, stopping between stages once ctx is
+// done.
+func (p *Pipeline) Apply(ctx context.Context, img image.Image) (image.Image, error) {
+	for _, stage := range p.stages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		img = stage(img)
+	}
+	return img, nil
+}
+
+// Preview runs the pipeline and returns the input followed by the
+// output of every stage.
+func (p *Pipeline) Preview(img image.Image) []PreviewStep {
+	steps := []PreviewStep{{Stage: "input", Image: img}}
+	for i, stage := range p.stages {
+		img = stage(img)
+		steps = append(steps, PreviewStep{Stage: p.names[i], Image: img})
+	}
+	return steps
+}
+
+// luminance returns the 8-bit luminance of every pixel, row-major.
+func luminance(img *image.NRGBA) []uint8 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	lum := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			r, g, b := float64(row[x*4]), float64(row[x*4+1]), float64(row[x*4+2])
+			lum[y*w+x] = uint8(0.299*r + 0.587*g + 0.114*b + 0.5)
+		}
+	}
+	return lum
+}
+
+// fromLuminance builds an opaque gray image from row-major values.
+func fromLuminance(lum []uint8, w, h int) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i, v := range lum {
+		out.Pix[i*4], out.Pix[i*4+1], out.Pix[i*4+2], out.Pix[i*4+3] = v, v, v, 255
+	}
+	return out
+}
+
+// otsuThreshold picks the threshold that maximizes the between-class
+// variance of the luminance histogram.
+func otsuThreshold(lum []uint8) uint8 {
+	var hist [256]int
+	for _, v := range lum {
+		hist[v]++
+	}
+
+	total := len(lum)
+	sum := 0
+	for i, n := range hist {
+		sum += i * n
+	}
+
+	var best uint8
+	bestVar := -1.0
+	sumB, weightB := 0, 0
+	for t := 0; t < 256; t++ {
+		weightB += hist[t]
+		if weightB == 0 {
+			continue
+		}
+		weightF := total - weightB
+		if weightF == 0 {
+			break
+		}
+		sumB += t * hist[t]
+
+		meanB := float64(sumB) / float64(weightB)
+		meanF := float64(sum-sumB) / float64(weightF)
+		between := float64(weightB) * float64(weightF) * (meanB - meanF) * (meanB - meanF)
+		if between > bestVar {
+			bestVar = between
+			best = uint8(t)
+		}
+	}
+	return best
+}
+
+func binarizeOtsu(img image.Image) *image.NRGBA {
+	src := imaging.Clone(img)
+	b := src.Bounds()
+	lum := luminance(src)
+
+	threshold := otsuThreshold(lum)
+	for i, v := range lum {
+		if v > threshold {
+			lum[i] = 255
+		} else {
+			lum[i] = 0
+		}
+	}
+	return fromLuminance(lum, b.Dx(), b.Dy())
+}
+
+// binarizeAdaptive thresholds each pixel against the mean of the
+// surrounding (2*radius+1)^2 window minus offset, which copes with
+// uneven lighting across the tooltip.
+func binarizeAdaptive(img image.Image, radius int, offset float64) *image.NRGBA {
+	src := imaging.Clone(img)
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	lum := luminance(src)
+
+	// Summed-area table with a zero row and column
+	integral := make([]int, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		rowSum := 0
+		for x := 0; x < w; x++ {
+			rowSum += int(lum[y*w+x])
+			integral[(y+1)*(w+1)+x+1] = integral[y*(w+1)+x+1] + rowSum
+		}
+	}
+
+	out := make([]uint8, len(lum))
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, w)
+			area := (x1 - x0) * (y1 - y0)
+			sum := integral[y1*(w+1)+x1] - integral[y0*(w+1)+x1] - integral[y1*(w+1)+x0] + integral[y0*(w+1)+x0]
+			if float64(lum[y*w+x]) > float64(sum)/float64(area)-offset {
+				out[y*w+x] = 255
+			}
+		}
+	}
+	return fromLuminance(out, w, h)
+}
+
+// medianFilter replaces each pixel's luminance with the median of its
+// neighbourhood, removing speckle without blurring glyph edges much.
+func medianFilter(img image.Image, radius int) *image.NRGBA {
+	src := imaging.Clone(img)
+	if radius < 1 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	lum := luminance(src)
+	out := make([]uint8, len(lum))
+	window := make([]uint8, 0, (2*radius+1)*(2*radius+1))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			window = window[:0]
+			for dy := -radius; dy <= radius; dy++ {
+				yy := min(max(y+dy, 0), h-1)
+				for dx := -radius; dx <= radius; dx++ {
+					xx := min(max(x+dx, 0), w-1)
+					window = append(window, lum[yy*w+xx])
+				}
+			}
+			sort.Slice(window, func(i, j int) bool { return window[i] < window[j] })
+			out[y*w+x] = window[len(window)/2]
+		}
+	}
+	return fromLuminance(out, w, h)
+}
+
+func isolateChannel(img image.Image, pick func(r, g, b uint8) uint8) *image.NRGBA {
+	src := imaging.Clone(img)
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	lum := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x := 0; x < w; x++ {
+			lum[y*w+x] = pick(row[x*4], row[x*4+1], row[x*4+2])
+		}
+	}
+	return fromLuminance(lum, w, h)
+}
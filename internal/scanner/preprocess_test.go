@@ -0,0 +1,221 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// apply runs a pipeline that is expected to finish.
+func apply(t *testing.T, p *Pipeline, img image.Image) image.Image {
+	t.Helper()
+	out, err := p.Apply(context.Background(), img)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return out
+}
+
+func TestPipeline_ApplyCancelled(t *testing.T) {
+	p := mustPipeline(t, config.StageSettings{Stage: "grayscale"}, config.StageSettings{Stage: "invert"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Apply(ctx, imaging.New(10, 10, color.White)); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewPipeline_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []config.StageSettings
+	}{
+		{"unknown stage", []config.StageSettings{{Stage: "blur"}}},
+		{"unknown binarize mode", []config.StageSettings{{Stage: "binarize", Mode: "magic"}}},
+		{"unknown channel", []config.StageSettings{{Stage: "channel", Mode: "alpha"}}},
+		{"zero gamma", []config.StageSettings{{Stage: "gamma", Params: map[string]float64{"gamma": 0}}}},
+		{"negative upscale", []config.StageSettings{{Stage: "upscale", Params: map[string]float64{"factor": -1}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPipeline(tt.stages); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPipeline_DefaultMatchesLegacyChain(t *testing.T) {
+	img := imaging.Crop(loadFixture(t, "tooltip_right.png"), staticBox())
+
+	pipeline, err := NewPipeline(config.DefaultSettings().Scanner.Preprocessing)
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+
+	got := imaging.Clone(apply(t, pipeline, img))
+	want := imaging.Clone(tooltipImage(t))
+	if !got.Bounds().Eq(want.Bounds()) {
+		t.Fatalf("bounds = %v, want %v", got.Bounds(), want.Bounds())
+	}
+	for i := range got.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("pixel data differs at byte %d", i)
+		}
+	}
+}
+
+func TestPipeline_Geometry(t *testing.T) {
+	img := imaging.New(100, 50, color.White)
+
+	tests := []struct {
+		stage config.StageSettings
+		want  image.Point
+	}{
+		{config.StageSettings{Stage: "upscale", Params: map[string]float64{"factor": 2}}, image.Pt(200, 100)},
+		{config.StageSettings{Stage: "upscale", Params: map[string]float64{"factor": 1.5}}, image.Pt(150, 75)},
+		{config.StageSettings{Stage: "crop", Params: map[string]float64{"left": 10, "top": 5, "right": 20}}, image.Pt(70, 45)},
+		{config.StageSettings{Stage: "crop", Params: map[string]float64{"left": 60, "right": 60}}, image.Pt(100, 50)},
+		{config.StageSettings{Stage: "pad", Params: map[string]float64{"size": 8}}, image.Pt(116, 66)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stage.Stage, func(t *testing.T) {
+			pipeline, err := NewPipeline([]config.StageSettings{tt.stage})
+			if err != nil {
+				t.Fatalf("NewPipeline failed: %v", err)
+			}
+			if got := apply(t, pipeline, img).Bounds().Size(); got != tt.want {
+				t.Errorf("size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOtsuThreshold(t *testing.T) {
+	// Two clusters around 40 and 200
+	var lum []uint8
+	for i := 0; i < 100; i++ {
+		lum = append(lum, uint8(35+i%10), uint8(195+i%10))
+	}
+
+	threshold := otsuThreshold(lum)
+	if threshold < 44 || threshold >= 195 {
+		t.Errorf("threshold = %d, want between the clusters", threshold)
+	}
+}
+
+func TestBinarize_Otsu(t *testing.T) {
+	// Dark left half, light right half, each with a vertical gradient
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			v := uint8(30 + y)
+			if x >= 20 {
+				v = uint8(180 + y)
+			}
+			img.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+
+	pipeline, err := NewPipeline([]config.StageSettings{{Stage: "binarize", Mode: "otsu"}})
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+	out := imaging.Clone(apply(t, pipeline, img))
+
+	if v := out.NRGBAAt(2, 10).R; v != 0 {
+		t.Errorf("dark pixel = %d, want 0", v)
+	}
+	if v := out.NRGBAAt(37, 10).R; v != 255 {
+		t.Errorf("light pixel = %d, want 255", v)
+	}
+}
+
+func TestBinarize_Adaptive(t *testing.T) {
+	// A dark stroke on a background that brightens from left to right,
+	// so no single global threshold separates the two everywhere
+	img := image.NewNRGBA(image.Rect(0, 0, 60, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 60; x++ {
+			v := uint8(60 + 3*x)
+			if y == 10 {
+				v -= 50
+			}
+			img.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+
+	pipeline, err := NewPipeline([]config.StageSettings{
+		{Stage: "binarize", Mode: "adaptive", Params: map[string]float64{"radius": 5, "offset": 10}},
+	})
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+	out := imaging.Clone(apply(t, pipeline, img))
+
+	for _, x := range []int{5, 30, 55} {
+		if v := out.NRGBAAt(x, 10).R; v != 0 {
+			t.Errorf("stroke at x=%d = %d, want 0", x, v)
+		}
+		if v := out.NRGBAAt(x, 3).R; v != 255 {
+			t.Errorf("background at x=%d = %d, want 255", x, v)
+		}
+	}
+}
+
+func TestDenoise_RemovesSpeckle(t *testing.T) {
+	img := imaging.New(9, 9, color.Black)
+	img.SetNRGBA(4, 4, color.NRGBA{255, 255, 255, 255})
+
+	out := medianFilter(img, 1)
+	if v := out.NRGBAAt(4, 4).R; v != 0 {
+		t.Errorf("speckle = %d, want 0", v)
+	}
+}
+
+func TestChannel(t *testing.T) {
+	img := imaging.New(2, 2, color.NRGBA{200, 50, 10, 255})
+
+	tests := map[string]uint8{"red": 200, "green": 50, "blue": 10, "max": 200}
+	for mode, want := range tests {
+		pipeline, err := NewPipeline([]config.StageSettings{{Stage: "channel", Mode: mode}})
+		if err != nil {
+			t.Fatalf("%s: NewPipeline failed: %v", mode, err)
+		}
+		out := imaging.Clone(apply(t, pipeline, img))
+		if got := out.NRGBAAt(0, 0); got.R != want || got.G != want || got.B != want {
+			t.Errorf("%s: pixel = %v, want gray %d", mode, got, want)
+		}
+	}
+}
+
+func TestPipeline_Preview(t *testing.T) {
+	pipeline, err := NewPipeline(config.DefaultSettings().Scanner.Preprocessing)
+	if err != nil {
+		t.Fatalf("NewPipeline failed: %v", err)
+	}
+
+	steps := pipeline.Preview(imaging.New(10, 10, color.White))
+
+	want := []string{"input", "grayscale", "invert", "contrast", "sharpen"}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(want))
+	}
+	for i, step := range steps {
+		if step.Stage != want[i] {
+			t.Errorf("step %d = %q, want %q", i, step.Stage, want[i])
+		}
+	}
+	if v := imaging.Clone(steps[2].Image).NRGBAAt(0, 0).R; v != 0 {
+		t.Errorf("inverted white = %d, want 0", v)
+	}
+}
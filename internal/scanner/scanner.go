@@ -1,102 +1,369 @@
 package scanner
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"image"
-	"image/png"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
 
 	"arc-scanner/internal/config"
 
-	"github.com/disintegration/imaging"
 	"github.com/kbinani/screenshot"
 )
 
+// Scanner captures and reads the screen. Every call stops early with the
+// context's error once it is cancelled or past its deadline.
 type Scanner interface {
-	TakeScreenshot(x, y int) (image.Image, error)
-	ProcessImage(img image.Image) (string, error)
+	TakeScreenshot(ctx context.Context, x, y int) (image.Image, error)
+	ProcessImage(ctx context.Context, img image.Image) (string, error)
+	Scan(ctx context.Context, x, y int, score Scorer) (ScanResult, error)
+	Rescan(ctx context.Context, x, y int, score Scorer) (ScanResult, error)
+	ScanGrid(ctx context.Context, x, y int) ([]GridSlot, error)
+	Probe(ctx context.Context, x, y int) (image.Image, error)
+}
+
+// Setup describes how a scanner runs OCR.
+type Setup struct {
+	Engine        string // config.OCREngineLib or config.OCREngineExec
+	TesseractPath string // may be the bare binary name when none was found
+	TessdataPath  string // empty for tesseract's default location
 }
 
 type TesseractScanner struct {
-	tesseractPath string
-	tessdataPath  string
+	setup         Setup
+	engine        OCREngine
+	pipeline      *Pipeline
+	detectTooltip bool
+	uiScale       float64
+
+	variants          []variant
+	adaptiveThreshold float64
+	adaptiveBudget    time.Duration
+
+	cache *resultCache // nil when disabled
+	vocab *vocabulary  // nil when disabled
+
+	mu          sync.Mutex
+	lastCapture image.Image
+	cursorSpace image.Point
 }
 
-func New() *TesseractScanner {
+func New(settings config.ScannerSettings) *TesseractScanner {
 	tesseractPath := findTesseractPath()
 	tessdataPath := findTessdataPath()
+	vocab := newVocabulary(settings)
+	engine := newEngine(settings.OCREngine, tesseractPath, tessdataPath, vocab)
+	setup := Setup{
+		Engine:        engine.Name(),
+		TesseractPath: tesseractPath,
+		TessdataPath:  tessdataPath,
+	}
+
+	// Every worker owns an engine of the kind picked above: a
+	// libtesseract client per worker keeps its model loaded, CLI workers
+	// bound how many tesseract processes run at once
+	if settings.OCRWorkers > 0 {
+		kind := engine.Name()
+		engine.Close()
+		engine = NewPool(
+			settings.OCRWorkers,
+			time.Duration(settings.OCRTimeoutMs)*time.Millisecond,
+			func() OCREngine { return newEngine(kind, tesseractPath, tessdataPath, vocab) },
+		)
+	}
+
+	pipeline, err := NewPipeline(settings.Preprocessing)
+	if err != nil {
+		slog.Warn("invalid preprocessing settings, using defaults", "error", err)
+		pipeline, _ = NewPipeline(config.DefaultSettings().Scanner.Preprocessing)
+	}
+
+	var variants []variant
+	for _, v := range settings.Variants {
+		p, err := NewPipeline(v.Preprocessing)
+		if err != nil {
+			slog.Warn("skipping invalid OCR variant", "variant", v.Name, "error", err)
+			continue
+		}
+		variants = append(variants, variant{name: v.Name, pipeline: p})
+	}
 
 	slog.Info("scanner initialized",
+		"engine", engine.Name(),
 		"tesseract", tesseractPath,
-		"tessdata", tessdataPath)
+		"tessdata", tessdataPath,
+		"variants", len(variants))
+
+	var cache *resultCache
+	if settings.ScanCacheSize > 0 {
+		cache = newResultCache(settings.ScanCacheSize, settings.ScanCacheTolerance)
+	}
 
 	return &TesseractScanner{
-		tesseractPath: tesseractPath,
-		tessdataPath:  tessdataPath,
+		setup:         setup,
+		engine:        engine,
+		pipeline:      pipeline,
+		detectTooltip: settings.TooltipDetection,
+		uiScale:       settings.UIScale,
+
+		variants:          variants,
+		adaptiveThreshold: settings.AdaptiveThreshold,
+		adaptiveBudget:    time.Duration(settings.AdaptiveBudgetMs) * time.Millisecond,
+
+		cache: cache,
+		vocab: vocab,
 	}
 }
 
-func (s *TesseractScanner) TakeScreenshot(x, y int) (image.Image, error) {
-	captureRect := image.Rect(
-		x+config.OcrBoxXOffset,
-		y-config.OcrBoxYOffset,
-		x+config.OcrBoxXOffset+config.OcrBoxWidth,
-		y-config.OcrBoxYOffset+config.OcrBoxHeight,
-	)
+// SetVocabulary writes the item words and patterns Tesseract's
+// dictionary is extended with to dir, replacing the previous ones, and
+// drops cached results read with the old item data. It does nothing
+// when the vocabulary is disabled.
+func (s *TesseractScanner) SetVocabulary(dir string, words, patterns []string) error {
+	if s.vocab == nil {
+		return nil
+	}
+	if err := s.vocab.write(dir, words, patterns); err != nil {
+		return err
+	}
+	if s.cache != nil {
+		s.cache.clear()
+	}
+	return nil
+}
 
-	img, err := screenshot.CaptureRect(captureRect)
+// Setup returns the OCR engine and tesseract paths in use.
+func (s *TesseractScanner) Setup() Setup {
+	return s.setup
+}
+
+func (s *TesseractScanner) TakeScreenshot(ctx context.Context, x, y int) (image.Image, error) {
+	c, err := s.capture(ctx, x, y)
 	if err != nil {
-		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+		return nil, err
 	}
+	img := c.img
+
+	s.mu.Lock()
+	s.lastCapture = img
+	s.mu.Unlock()
 
-	// Preprocess image for better OCR accuracy
-	processed := imaging.Grayscale(img)
-	processed = imaging.Invert(processed)
-	processed = imaging.AdjustContrast(processed, config.ContrastLevel)
-	processed = imaging.Sharpen(processed, config.SharpenLevel)
+	return s.pipeline.Apply(ctx, img)
+}
+
+// Scan captures the tooltip next to the cursor and reads it, falling
+// back to the adaptive variants when the first pass scores low. A capture
+// that looks like a recent one reuses its read instead of running OCR.
+func (s *TesseractScanner) Scan(ctx context.Context, x, y int, score Scorer) (ScanResult, error) {
+	return s.scan(ctx, x, y, score, false)
+}
 
-	return processed, nil
+// Rescan is Scan without the result cache. Its read replaces the cached
+// one, so it also fixes a bad read that would otherwise be reused.
+func (s *TesseractScanner) Rescan(ctx context.Context, x, y int, score Scorer) (ScanResult, error) {
+	return s.scan(ctx, x, y, score, true)
 }
 
-func (s *TesseractScanner) ProcessImage(img image.Image) (string, error) {
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
-		return "", fmt.Errorf("failed to encode image: %w", err)
+func (s *TesseractScanner) scan(ctx context.Context, x, y int, score Scorer, force bool) (ScanResult, error) {
+	start := time.Now()
+	c, err := s.capture(ctx, x, y)
+	if err != nil {
+		return ScanResult{Raw: c.raw, Display: c.display}, err
 	}
+	captured := time.Since(start)
+	img := c.img
 
-	cmd := exec.Command(
-		s.tesseractPath,
-		"stdin",  // Read from stdin
-		"stdout", // Output to stdout
-		"--psm", config.TesseractPSM,
-		"--oem", config.TesseractOEM,
-		"-c", "tessedit_char_whitelist="+config.TesseractWhitelist,
-	)
+	s.mu.Lock()
+	s.lastCapture = img
+	s.mu.Unlock()
+
+	var hash tooltipHash
+	if s.cache != nil {
+		hash = newTooltipHash(img)
+		if cached, ok := s.cache.get(hash); ok && !force {
+			slog.Debug("scan cache hit", "text", cached.Text)
+			cached.Cached = true
+			cached.Display = c.display
+			cached.Raw, cached.Tooltip = c.raw, c.tooltip
+			cached.Capture = img
+			cached.Slot = c.slot
+			cached.Timings = Timings{Capture: captured}
+			return cached, nil
+		}
+	}
+
+	result, err := s.Identify(ctx, img, score)
+	if err == nil && s.cache != nil {
+		s.cache.put(hash, result)
+	}
+	result.Display = c.display
+	result.Raw, result.Tooltip = c.raw, c.tooltip
+	result.Capture = img
+	result.Slot = c.slot
+	result.Timings.Capture = captured
+	return result, err
+}
+
+// Probe captures the tooltip detection area next to the cursor without
+// cropping or reading it, for cheap checks before a scan.
+func (s *TesseractScanner) Probe(ctx context.Context, x, y int) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	g, err := s.geometry()
+	if err != nil {
+		return nil, err
+	}
+	return captureNormalized(g.Place(g.CursorToCapture(image.Pt(x, y)), detectBox()))
+}
+
+// Preview runs the last captured image through a preprocessing pipeline
+// and returns every intermediate image. Nil stages use the configured
+// pipeline, so alternatives can be tried without restarting.
+func (s *TesseractScanner) Preview(stages []config.StageSettings) ([]PreviewStep, error) {
+	s.mu.Lock()
+	img := s.lastCapture
+	s.mu.Unlock()
+
+	if img == nil {
+		return nil, fmt.Errorf("no capture to preview yet")
+	}
 
-	// Hide console window on Windows
-	hideConsoleWindow(cmd)
+	pipeline := s.pipeline
+	if stages != nil {
+		var err error
+		if pipeline, err = NewPipeline(stages); err != nil {
+			return nil, err
+		}
+	}
+
+	return pipeline.Preview(img), nil
+}
+
+// SetCursorSpace tells the scanner the size of the main display in
+// cursor coordinates, so cursor positions can be converted when they
+// differ from capture coordinates on scaled displays.
+func (s *TesseractScanner) SetCursorSpace(size image.Point) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursorSpace = size
+}
 
-	// Set tessdata location if using bundled version
-	if s.tessdataPath != "" {
-		cmd.Env = append(os.Environ(), "TESSDATA_PREFIX="+s.tessdataPath)
+// geometry returns the capture geometry of the current display layout.
+// No displays means the screen can't be reached at all, e.g. a Wayland
+// session without XWayland.
+func (s *TesseractScanner) geometry() (Geometry, error) {
+	s.mu.Lock()
+	cursorSpace := s.cursorSpace
+	s.mu.Unlock()
+
+	displays := make([]image.Rectangle, screenshot.NumActiveDisplays())
+	for i := range displays {
+		displays[i] = screenshot.GetDisplayBounds(i)
+	}
+	if len(displays) == 0 {
+		return Geometry{}, fmt.Errorf("%w: no display found", ErrCaptureDenied)
 	}
 
-	cmd.Stdin = &buf
+	return NewGeometry(displays, cursorSpace, s.uiScale), nil
+}
 
-	output, err := cmd.Output()
+// capture grabs the tooltip area next to the cursor on the display under
+// it and returns it in reference pixels, along with the hovered slot and
+// that display. With detection on, a wider area is captured and cropped
+// to the tooltip panel, which handles tooltips that flip to the left
+// near the screen edge. Taking a screenshot can't be interrupted, so ctx
+// is checked before and after.
+func (s *TesseractScanner) capture(ctx context.Context, x, y int) (captured, error) {
+	if err := ctx.Err(); err != nil {
+		return captured{}, fmt.Errorf("capture stopped: %w", err)
+	}
+	g, err := s.geometry()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("OCR failed: %s (stderr: %s)", err, string(exitErr.Stderr))
+		return captured{}, err
+	}
+	cursor := g.CursorToCapture(image.Pt(x, y))
+
+	box := ocrBox()
+	if s.detectTooltip {
+		box = detectBox()
+	}
+
+	// Only capture what is on the cursor's display, so the box never
+	// spans two monitors with different scales
+	place := g.Place(cursor, box)
+	c := captured{display: place.Display}
+	if place.Visible.Empty() {
+		return c, fmt.Errorf("failed to capture screenshot: cursor %v is outside every display", cursor)
+	}
+
+	c.raw, err = captureNormalized(place)
+	if err != nil {
+		return c, err
+	}
+	if err := ctx.Err(); err != nil {
+		return c, fmt.Errorf("capture stopped: %w", err)
+	}
+
+	if !s.detectTooltip {
+		c.img = c.raw
+		// The fixed box starts at the cursor, so the slot needs its own capture
+		c.slot, err = captureNormalized(g.Place(cursor, slotBox()))
+		if err != nil {
+			slog.Debug("slot capture failed", "error", err)
 		}
-		return "", fmt.Errorf("OCR failed: %w", err)
+		return c, nil
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	c.img, c.slot, c.tooltip = SplitCapture(c.raw)
+	slog.Debug("tooltip detection",
+		"detected", !c.tooltip.Empty(),
+		"bounds", c.tooltip,
+		"display", place.Display,
+		"uiScale", place.Scale)
+	return c, nil
+}
+
+// captured is what capture grabbed: the raw capture, the part of it to
+// read, the hovered slot and the display they are on.
+type captured struct {
+	raw     image.Image
+	img     image.Image
+	slot    image.Image
+	tooltip image.Rectangle // within raw, empty when not detected
+	display image.Rectangle
+}
+
+// captureNormalized captures the visible part of a placed box and
+// returns it in reference pixels.
+func captureNormalized(place Placement) (image.Image, error) {
+	if place.Visible.Empty() {
+		return nil, fmt.Errorf("failed to capture screenshot: box %v is off screen", place.Full)
+	}
+	captured, err := screenshot.CaptureRect(place.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCaptureDenied, err)
+	}
+	if blankCapture(captured) {
+		return nil, fmt.Errorf("%w: %v", ErrBlankCapture, place.Visible)
+	}
+	return place.Normalize(captured), nil
+}
+
+func (s *TesseractScanner) ProcessImage(ctx context.Context, img image.Image) (string, error) {
+	result, err := s.engine.Recognize(ctx, img)
+	return result.Text, err
+}
+
+// Close releases the OCR engine.
+func (s *TesseractScanner) Close() error {
+	return s.engine.Close()
 }
 
 func findTesseractPath() string {
@@ -125,9 +392,37 @@ func findTessdataPath() string {
 			return tessdataPath
 		}
 	}
+	if isLinux() {
+		// Distro packages put the data in a versioned directory that a
+		// tesseract built from source doesn't look in
+		return findTessdataIn(systemTessdataDirs(), config.TesseractLang)
+	}
 	return "" // Let Tesseract use default
 }
 
+// findTessdataIn returns the first directory holding the traineddata for
+// lang, or "" if none does
+func findTessdataIn(dirs []string, lang string) string {
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, lang+".traineddata")); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// systemTessdataDirs lists the Linux tessdata locations, newest
+// tesseract-ocr version first
+func systemTessdataDirs() []string {
+	versioned, _ := filepath.Glob("/usr/share/tesseract-ocr/*/tessdata")
+	sort.Sort(sort.Reverse(sort.StringSlice(versioned)))
+	return append(versioned,
+		"/usr/share/tesseract-ocr/tessdata",
+		"/usr/share/tessdata",
+		"/usr/local/share/tessdata",
+	)
+}
+
 func getBundledPath(exe, subdir, filename string) string {
 	var basePath string
 	if isWindows() {
@@ -146,6 +441,10 @@ func isWindows() bool {
 	return filepath.Separator == '\\'
 }
 
+func isLinux() bool {
+	return runtime.GOOS == "linux"
+}
+
 func tesseractBinaryName() string {
 	if isWindows() {
 		return "tesseract.exe"
@@ -160,6 +459,12 @@ func systemTesseractPaths() []string {
 			"C:\\Program Files (x86)\\Tesseract-OCR\\tesseract.exe",
 		}
 	}
+	if isLinux() {
+		return []string{
+			"/usr/bin/tesseract",
+			"/usr/local/bin/tesseract",
+		}
+	}
 	return []string{
 		"/opt/homebrew/bin/tesseract",
 		"/usr/local/bin/tesseract",
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindTessdataIn(t *testing.T) {
+	root := t.TempDir()
+	empty := filepath.Join(root, "4.00", "tessdata")
+	current := filepath.Join(root, "5", "tessdata")
+	for _, dir := range []string{empty, current} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(current, "eng.traineddata"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dirs []string
+		lang string
+		want string
+	}{
+		{"skips dir without language", []string{empty, current}, "eng", current},
+		{"missing language", []string{empty, current}, "deu", ""},
+		{"missing dir", []string{filepath.Join(root, "nope")}, "eng", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findTessdataIn(tt.dirs, tt.lang); got != tt.want {
+				t.Errorf("findTessdataIn = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
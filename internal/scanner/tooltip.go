@@ -0,0 +1,166 @@
+package scanner
+
+import (
+	"image"
+
+	"arc-scanner/internal/config"
+
+	"github.com/disintegration/imaging"
+)
+
+// Tooltip detection thresholds, tuned on 8-bit luminance.
+const (
+	panelMaxLuminance = 55  // tooltip background is near-black
+	panelMaxGradient  = 6   // and flat, unlike the game scene behind it
+	panelMinWidth     = 150 // smallest tooltip we expect, in pixels
+	panelMinHeight    = 100
+	borderMinContrast = 40 // border must stand out from the panel
+	borderSearch      = 4  // how far outside the panel to look for it
+)
+
+// DetectTooltip looks for the tooltip panel in a capture: a large flat
+// dark rectangle framed by a lighter border. It returns the panel
+// bounds including the border, or false if no panel was found.
+func DetectTooltip(img image.Image) (image.Rectangle, bool) {
+	gray := imaging.Grayscale(img)
+	bounds := gray.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < panelMinWidth || h < panelMinHeight {
+		return image.Rectangle{}, false
+	}
+
+	lum := func(x, y int) int {
+		// Grayscale leaves R == G == B
+		return int(gray.Pix[y*gray.Stride+x*4])
+	}
+
+	// Mark flat dark pixels and count them per row
+	mask := make([]bool, w*h)
+	rowCount := make([]int, h)
+	for y := 0; y < h-1; y++ {
+		for x := 0; x < w-1; x++ {
+			l := lum(x, y)
+			if l > panelMaxLuminance {
+				continue
+			}
+			if abs(l-lum(x+1, y)) > panelMaxGradient || abs(l-lum(x, y+1)) > panelMaxGradient {
+				continue
+			}
+			mask[y*w+x] = true
+			rowCount[y]++
+		}
+	}
+
+	top, bottom := longestRun(rowCount, panelMinWidth)
+	if bottom-top < panelMinHeight {
+		return image.Rectangle{}, false
+	}
+
+	// Text lines punch holes in the mask, so only ask for half the rows
+	colCount := make([]int, w)
+	for y := top; y < bottom; y++ {
+		for x := 0; x < w; x++ {
+			if mask[y*w+x] {
+				colCount[x]++
+			}
+		}
+	}
+
+	left, right := longestRun(colCount, (bottom-top)/2)
+	if right-left < panelMinWidth {
+		return image.Rectangle{}, false
+	}
+
+	panel := image.Rect(left, top, right, bottom)
+	if borderContrast(lum, panel, w, h) < borderMinContrast {
+		return image.Rectangle{}, false
+	}
+
+	framed := image.Rect(left-borderSearch, top-borderSearch, right+borderSearch, bottom+borderSearch)
+	framed = framed.Intersect(image.Rect(0, 0, w, h))
+	return framed.Add(bounds.Min), true
+}
+
+// SplitCapture cuts a DetectBox capture up like a scan does: img is the
+// tooltip, or the static OcrBox when none is detected, and slot the
+// hovered inventory slot at the cursor. tooltip is the detected panel,
+// empty when there is none.
+func SplitCapture(raw image.Image) (img, slot image.Image, tooltip image.Rectangle) {
+	// The detection box is centered around the cursor and contains the slot
+	slot = imaging.Crop(raw, slotBox().Sub(detectBox().Min).Add(raw.Bounds().Min))
+
+	tooltip, detected := DetectTooltip(raw)
+	if !detected {
+		return imaging.Crop(raw, staticBox().Add(raw.Bounds().Min)), slot, image.Rectangle{}
+	}
+	return imaging.Crop(raw, tooltip), slot, tooltip
+}
+
+// staticBox is the fixed OcrBox expressed in the coordinates of a
+// DetectBox capture.
+func staticBox() image.Rectangle {
+	x := config.DetectBoxXOffset + config.OcrBoxXOffset
+	y := config.DetectBoxYOffset - config.OcrBoxYOffset
+	return image.Rect(x, y, x+config.OcrBoxWidth, y+config.OcrBoxHeight)
+}
+
+// borderContrast compares the brightest ring just outside the panel
+// with the panel's own average luminance.
+func borderContrast(lum func(x, y int) int, panel image.Rectangle, w, h int) int {
+	inner, innerN := 0, 0
+	for y := panel.Min.Y; y < panel.Max.Y; y += 4 {
+		for x := panel.Min.X; x < panel.Max.X; x += 4 {
+			inner += lum(x, y)
+			innerN++
+		}
+	}
+	if innerN == 0 {
+		return 0
+	}
+
+	best := 0
+	for d := 1; d <= borderSearch; d++ {
+		ring := panel.Inset(-d).Intersect(image.Rect(0, 0, w-1, h-1))
+		sum, n := 0, 0
+		for x := ring.Min.X; x < ring.Max.X; x++ {
+			sum += lum(x, ring.Min.Y) + lum(x, ring.Max.Y-1)
+			n += 2
+		}
+		for y := ring.Min.Y; y < ring.Max.Y; y++ {
+			sum += lum(ring.Min.X, y) + lum(ring.Max.X-1, y)
+			n += 2
+		}
+		if n > 0 && sum/n > best {
+			best = sum / n
+		}
+	}
+
+	return best - inner/innerN
+}
+
+// longestRun returns the [start, end) of the longest stretch of counts
+// that are all at least min.
+func longestRun(counts []int, min int) (int, int) {
+	bestStart, bestEnd := 0, 0
+	start := -1
+	for i := 0; i <= len(counts); i++ {
+		if i < len(counts) && counts[i] >= min {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, i
+		}
+		start = -1
+	}
+	return bestStart, bestEnd
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
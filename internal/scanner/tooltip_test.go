@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"encoding/json"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectTooltip(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    image.Rectangle
+		found   bool
+	}{
+		{fixture: "tooltip_right.png", want: image.Rect(470, 120, 880, 540), found: true},
+		{fixture: "tooltip_left.png", want: image.Rect(30, 140, 430, 560), found: true},
+		{fixture: "no_tooltip.png", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, ok := DetectTooltip(loadFixture(t, tt.fixture))
+			if ok != tt.found {
+				t.Fatalf("DetectTooltip found = %v, want %v (rect %v)", ok, tt.found, got)
+			}
+			if !ok {
+				return
+			}
+			if !rectNear(got, tt.want, 6) {
+				t.Errorf("DetectTooltip = %v, want ~%v", got, tt.want)
+			}
+		})
+	}
+}
+
+// capturesDir holds real in-game captures, see testdata/README.md.
+const capturesDir = "testdata/captures"
+
+// captureLabel describes one real capture. Tooltip uses the format of
+// the tooltip field in a debug capture's scan.json.
+type captureLabel struct {
+	Image      string           `json:"image"`
+	Resolution string           `json:"resolution"`
+	Rarity     string           `json:"rarity,omitempty"`
+	Tooltip    *image.Rectangle `json:"tooltip"` // nil when none is shown
+}
+
+func TestDetectTooltip_Captures(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(capturesDir, "labels.json"))
+	if os.IsNotExist(err) {
+		t.Skip("no real captures in " + capturesDir)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []captureLabel
+	if err := json.Unmarshal(data, &labels); err != nil {
+		t.Fatalf("labels.json unreadable: %v", err)
+	}
+
+	for _, label := range labels {
+		t.Run(label.Image, func(t *testing.T) {
+			img := loadFixture(t, filepath.Join("captures", label.Image))
+			got, ok := DetectTooltip(img)
+			if ok != (label.Tooltip != nil) {
+				t.Fatalf("%s %s: found = %v (rect %v), want %v", label.Resolution, label.Rarity, ok, got, label.Tooltip)
+			}
+			if ok && !rectNear(got, *label.Tooltip, 6) {
+				t.Errorf("%s %s: DetectTooltip = %v, want ~%v", label.Resolution, label.Rarity, got, *label.Tooltip)
+			}
+		})
+	}
+}
+
+func TestSplitCapture(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    image.Rectangle // crop in capture pixels
+	}{
+		{fixture: "tooltip_right.png", want: image.Rect(470, 120, 880, 540)},
+		{fixture: "no_tooltip.png", want: staticBox()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			img, slot, tooltip := SplitCapture(loadFixture(t, tt.fixture))
+			if tooltip.Empty() != (tt.want == staticBox()) {
+				t.Errorf("tooltip = %v, want detection only with a panel", tooltip)
+			}
+			if size := img.Bounds().Size(); abs(size.X-tt.want.Dx()) > 12 || abs(size.Y-tt.want.Dy()) > 12 {
+				t.Errorf("crop size = %v, want ~%v", size, tt.want.Size())
+			}
+			if slot.Bounds().Size() != slotBox().Size() {
+				t.Errorf("slot size = %v, want %v", slot.Bounds().Size(), slotBox().Size())
+			}
+		})
+	}
+}
+
+func rectNear(a, b image.Rectangle, tolerance int) bool {
+	return abs(a.Min.X-b.Min.X) <= tolerance && abs(a.Min.Y-b.Min.Y) <= tolerance &&
+		abs(a.Max.X-b.Max.X) <= tolerance && abs(a.Max.Y-b.Max.Y) <= tolerance
+}
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"image"
+	"strconv"
+	"strings"
+
+	"arc-scanner/internal/ocr"
+)
+
+// Column indexes of tesseract's TSV output.
+const (
+	tsvLevel = iota
+	tsvPage
+	tsvBlock
+	tsvPar
+	tsvLine
+	tsvWord
+	tsvLeft
+	tsvTop
+	tsvWidth
+	tsvHeight
+	tsvConf
+	tsvText
+	tsvColumns
+)
+
+// tsvWordLevel is the TSV level of rows describing a single word.
+const tsvWordLevel = "5"
+
+// parseTSV builds the layout of tesseract's TSV output from its word
+// rows. The text has one line per OCR line and a blank line between
+// paragraphs.
+func parseTSV(output string) OCRResult {
+	var b layoutBuilder
+
+	for _, row := range strings.Split(output, "\n") {
+		cols := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if len(cols) < tsvColumns || cols[tsvLevel] != tsvWordLevel {
+			continue
+		}
+
+		num := func(col int) int {
+			n, _ := strconv.Atoi(cols[col])
+			return n
+		}
+
+		left, top := num(tsvLeft), num(tsvTop)
+		conf, err := strconv.ParseFloat(cols[tsvConf], 64)
+		if err != nil {
+			conf = -1
+		}
+
+		b.add(num(tsvBlock), num(tsvPar), num(tsvLine), ocr.Word{
+			Text:       cols[tsvText],
+			Box:        image.Rect(left, top, left+num(tsvWidth), top+num(tsvHeight)),
+			Confidence: conf,
+		})
+	}
+
+	return b.result()
+}
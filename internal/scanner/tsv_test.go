@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"arc-scanner/internal/ocr"
+)
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t450\t480\t-1\t\n" +
+	"2\t1\t1\t0\t0\t0\t20\t30\t200\t40\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t20\t30\t200\t20\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t20\t30\t60\t20\t96.5\tARC\n" +
+	"5\t1\t1\t1\t1\t2\t90\t30\t90\t20\t91.5\tALLOY\n" +
+	"5\t1\t1\t1\t2\t1\t20\t60\t40\t20\t80\t3/10\n" +
+	"5\t1\t2\t1\t1\t1\t20\t120\t120\t20\t60\tMaterial\n" +
+	"5\t1\t2\t1\t1\t2\t150\t120\t10\t20\t-1\t \n"
+
+func TestParseTSV(t *testing.T) {
+	result := parseTSV(sampleTSV)
+
+	want := "ARC ALLOY\n3/10\n\nMaterial"
+	if result.Text != want {
+		t.Errorf("Text = %q, want %q", result.Text, want)
+	}
+	if math.Abs(result.Confidence-82) > 0.001 {
+		t.Errorf("Confidence = %v, want 82", result.Confidence)
+	}
+}
+
+func TestParseTSV_Layout(t *testing.T) {
+	result := parseTSV(sampleTSV)
+
+	if len(result.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(result.Blocks))
+	}
+	title := result.Blocks[0]
+	if len(title.Lines) != 2 || title.Lines[0].Text() != "ARC ALLOY" || title.Lines[1].Text() != "3/10" {
+		t.Errorf("title lines = %+v", title.Lines)
+	}
+	if want := image.Rect(20, 30, 180, 80); title.Box != want {
+		t.Errorf("title box = %v, want %v", title.Box, want)
+	}
+	if want := image.Rect(20, 30, 180, 50); title.Lines[0].Box != want {
+		t.Errorf("line box = %v, want %v", title.Lines[0].Box, want)
+	}
+	if got := title.Lines[0].Confidence(); math.Abs(got-94) > 0.001 {
+		t.Errorf("line confidence = %v, want 94", got)
+	}
+
+	words := result.Words()
+	if len(words) != 4 {
+		t.Fatalf("got %d words, want 4", len(words))
+	}
+	want := ocr.Word{Text: "ALLOY", Box: image.Rect(90, 30, 180, 50), Confidence: 91.5}
+	if words[1] != want {
+		t.Errorf("words[1] = %+v, want %+v", words[1], want)
+	}
+}
+
+func TestParseTSV_Empty(t *testing.T) {
+	result := parseTSV("level\tpage_num\n")
+	if result.Text != "" || result.Confidence != 0 || result.Blocks != nil {
+		t.Errorf("parseTSV(empty) = %+v, want zero result", result)
+	}
+}
@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"arc-scanner/internal/config"
+)
+
+// Files written to the vocabulary directory.
+const (
+	userWordsFile    = "arc.user-words"
+	userPatternsFile = "arc.user-patterns"
+	userConfigFile   = "arc.config" // the same settings for libtesseract
+)
+
+// vocabulary extends Tesseract's dictionary with the item vocabulary.
+// The CLI reads its files on every run and libtesseract when it is
+// initialized, so they are replaced by renaming: a read never sees a
+// half-written list.
+type vocabulary struct {
+	nonDictPenalty     float64
+	nonFreqDictPenalty float64
+
+	mu      sync.RWMutex
+	dir     string // empty until the first write
+	version int    // bumped on every write
+}
+
+// newVocabulary returns nil when the vocabulary is disabled.
+func newVocabulary(settings config.ScannerSettings) *vocabulary {
+	if !settings.UserVocabulary {
+		return nil
+	}
+	return &vocabulary{
+		nonDictPenalty:     settings.NonDictWordPenalty,
+		nonFreqDictPenalty: settings.NonFreqDictWordPenalty,
+	}
+}
+
+// write replaces the word and pattern lists, one entry per line, in dir.
+func (v *vocabulary) write(dir string, words, patterns []string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create vocabulary directory: %w", err)
+	}
+
+	files := map[string]string{
+		userWordsFile:    joinLines(words),
+		userPatternsFile: joinLines(patterns),
+		// Reloading it reinitializes libtesseract, so it also carries the
+		// page segmentation mode that NewLibEngine set on the client
+		userConfigFile: fmt.Sprintf("user_words_file %s\nuser_patterns_file %s\ntessedit_pageseg_mode %s\n%s",
+			filepath.Join(dir, userWordsFile), filepath.Join(dir, userPatternsFile), config.TesseractPSM, joinLines(v.params())),
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for name, content := range files {
+		if err := writeAtomic(filepath.Join(dir, name), content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	v.dir = dir
+	v.version++
+	return nil
+}
+
+// params are the dictionary weights as Tesseract "name value" pairs.
+func (v *vocabulary) params() []string {
+	return []string{
+		"language_model_penalty_non_dict_word " + strconv.FormatFloat(v.nonDictPenalty, 'f', -1, 64),
+		"language_model_penalty_non_freq_dict_word " + strconv.FormatFloat(v.nonFreqDictPenalty, 'f', -1, 64),
+	}
+}
+
+// args returns the tesseract CLI arguments, none before the first
+// write or when v is nil.
+func (v *vocabulary) args() []string {
+	if v == nil {
+		return nil
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.dir == "" {
+		return nil
+	}
+
+	args := []string{
+		"--user-words", filepath.Join(v.dir, userWordsFile),
+		"--user-patterns", filepath.Join(v.dir, userPatternsFile),
+	}
+	for _, param := range v.params() {
+		args = append(args, "-c", strings.Replace(param, " ", "=", 1))
+	}
+	return args
+}
+
+// configFile returns the libtesseract config file and the version it
+// was written at, or "" before the first write or when v is nil.
+func (v *vocabulary) configFile() (string, int) {
+	if v == nil {
+		return "", 0
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.dir == "" {
+		return "", 0
+	}
+	return filepath.Join(v.dir, userConfigFile), v.version
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// writeAtomic writes a file next to path and renames it into place.
+func writeAtomic(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
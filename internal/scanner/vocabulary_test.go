@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"arc-scanner/internal/config"
+)
+
+func TestVocabulary_Write(t *testing.T) {
+	if v := newVocabulary(config.ScannerSettings{}); v != nil || v.args() != nil {
+		t.Fatalf("disabled vocabulary = %v, want nil without args", v)
+	}
+
+	v := newVocabulary(config.DefaultSettings().Scanner)
+	if args := v.args(); args != nil {
+		t.Errorf("args before write = %v, want none", args)
+	}
+
+	dir := filepath.Join(t.TempDir(), "tesseract")
+	if err := v.write(dir, []string{"ARC", "ALLOY"}, []string{`\d\*/\d\*`}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	words, err := os.ReadFile(filepath.Join(dir, userWordsFile))
+	if err != nil || string(words) != "ARC\nALLOY\n" {
+		t.Errorf("words file = %q, %v", words, err)
+	}
+
+	args := v.args()
+	for _, want := range []string{"--user-words", filepath.Join(dir, userWordsFile), "--user-patterns", "language_model_penalty_non_dict_word=0.3"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("args = %v, missing %s", args, want)
+		}
+	}
+
+	path, version := v.configFile()
+	conf, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(conf), "user_patterns_file "+filepath.Join(dir, userPatternsFile)) {
+		t.Errorf("config file = %q, %v", conf, err)
+	}
+
+	// Rewriting bumps the version so libtesseract reloads, and leaves no
+	// temporary files behind
+	if err := v.write(dir, []string{"ANVIL"}, nil); err != nil {
+		t.Fatalf("rewrite failed: %v", err)
+	}
+	if _, next := v.configFile(); next != version+1 {
+		t.Errorf("version after rewrite = %d, want %d", next, version+1)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("vocabulary directory has %d files, want 3", len(entries))
+	}
+}
+
+func TestExecEngine_Vocabulary(t *testing.T) {
+	v := newVocabulary(config.DefaultSettings().Scanner)
+	if err := v.write(t.TempDir(), []string{"ARC", "ALLOY"}, []string{`\d\*/\d\*`}); err != nil {
+		t.Fatal(err)
+	}
+	engine := newExecEngine(requireTesseract(t), "", v)
+
+	result, err := engine.Recognize(context.Background(), tooltipImage(t))
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if !strings.Contains(result.Text, "ARC ALLOY") {
+		t.Errorf("Recognize = %q, want it to contain ARC ALLOY", result.Text)
+	}
+}
@@ -1,13 +1,17 @@
 package updater
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -137,19 +141,30 @@ func (u *Updater) CheckForUpdate() (*UpdateInfo, error) {
 
 // findAssetURL finds the download URL for the current platform
 func (u *Updater) findAssetURL(assets []GitHubAsset) string {
-	var platformKey string
-	switch runtime.GOOS {
+	return matchAsset(assets, runtime.GOOS, os.Getenv("APPIMAGE") != "")
+}
+
+// matchAsset picks the release asset for goos. On Linux an AppImage
+// install only takes an AppImage, anything else takes the tarball.
+func matchAsset(assets []GitHubAsset, goos string, appImage bool) string {
+	var platformKey, suffix string
+	switch goos {
 	case "darwin":
-		platformKey = "macos"
+		platformKey, suffix = "macos", ".zip"
 	case "windows":
-		platformKey = "windows"
+		platformKey, suffix = "windows", ".zip"
+	case "linux":
+		platformKey, suffix = "linux", ".tar.gz"
+		if appImage {
+			suffix = ".appimage"
+		}
 	default:
 		return ""
 	}
 
 	for _, asset := range assets {
 		name := strings.ToLower(asset.Name)
-		if strings.Contains(name, platformKey) && strings.HasSuffix(name, ".zip") {
+		if strings.Contains(name, platformKey) && strings.HasSuffix(name, suffix) {
 			return asset.BrowserDownloadURL
 		}
 	}
@@ -165,7 +180,7 @@ func (u *Updater) DownloadUpdate(ctx context.Context, info *UpdateInfo, progress
 		return fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
-	zipPath := filepath.Join(tempDir, "update.zip")
+	archivePath := filepath.Join(tempDir, "update"+archiveExt(info.DownloadURL))
 
 	// Download the file
 	req, err := http.NewRequestWithContext(ctx, "GET", info.DownloadURL, nil)
@@ -184,7 +199,7 @@ func (u *Updater) DownloadUpdate(ctx context.Context, info *UpdateInfo, progress
 	}
 
 	// Create output file
-	out, err := os.Create(zipPath)
+	out, err := os.Create(archivePath)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
@@ -217,9 +232,24 @@ func (u *Updater) DownloadUpdate(ctx context.Context, info *UpdateInfo, progress
 		}
 	}
 
-	// Extract the zip
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+
 	extractDir := filepath.Join(tempDir, "extracted")
-	if err := u.extractZip(zipPath, extractDir); err != nil {
+	switch archiveExt(info.DownloadURL) {
+	case ".tar.gz":
+		err = u.extractTarGz(archivePath, extractDir)
+	case ".AppImage":
+		// Nothing to unpack, the image replaces the running one as is
+		err = os.MkdirAll(extractDir, 0755)
+		if err == nil {
+			err = os.Rename(archivePath, filepath.Join(extractDir, "arc-scanner.AppImage"))
+		}
+	default:
+		err = u.extractZip(archivePath, extractDir)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to extract update: %w", err)
 	}
 
@@ -279,6 +309,84 @@ func (u *Updater) extractZip(zipPath, destDir string) error {
 	return nil
 }
 
+// archiveExt returns the archive type of a download URL: .tar.gz,
+// .AppImage or .zip
+func archiveExt(downloadURL string) string {
+	name := downloadURL
+	if parsed, err := url.Parse(downloadURL); err == nil {
+		name = path.Base(parsed.Path)
+	}
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ".tar.gz"
+	case strings.HasSuffix(name, ".appimage"):
+		return ".AppImage"
+	default:
+		return ".zip"
+	}
+}
+
+// extractTarGz extracts a gzipped tarball to the destination directory
+func (u *Updater) extractTarGz(archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fpath := filepath.Join(destDir, hdr.Name)
+
+		// Same check as extractZip, against entries like ../../bin/sh
+		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path: %s", fpath)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(fpath, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+				return err
+			}
+			outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(outFile, tr)
+			outFile.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %s in update", hdr.Name)
+		}
+	}
+}
+
 // GetDownloadedPath returns the path where the update was extracted
 func (u *Updater) GetDownloadedPath() string {
 	return u.downloadedPath
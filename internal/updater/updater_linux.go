@@ -0,0 +1,91 @@
+//go:build linux
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// updateScript waits for the app to exit, swaps in the new version and
+// relaunches it. Paths come in as arguments so they need no quoting:
+// $1 pid, $2 mode (appimage or tarball), $3 new file or directory,
+// $4 install target, $5 temp directory to remove.
+const updateScript = `#!/bin/sh
+# Wait for the app to exit
+while kill -0 "$1" 2>/dev/null; do sleep 0.2; done
+
+if [ "$2" = appimage ]; then
+	# Rename within the target directory so the swap is atomic
+	cp "$3" "$4.new" && chmod 755 "$4.new" && mv -f "$4.new" "$4"
+	"$4" >/dev/null 2>&1 &
+else
+	cp -R "$3"/. "$(dirname "$4")"/
+	"$4" >/dev/null 2>&1 &
+fi
+
+# Clean up temp files
+rm -rf "$5"
+rm -- "$0"
+`
+
+// ApplyUpdate applies the downloaded update on Linux
+// An AppImage install has its image replaced, a tarball install has the
+// extracted files copied over the directory holding the executable
+func (u *Updater) ApplyUpdate() error {
+	if u.downloadedPath == "" {
+		return fmt.Errorf("no update downloaded")
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	mode, source, target := "tarball", "", execPath
+	if appImage := os.Getenv("APPIMAGE"); appImage != "" {
+		// The executable lives in the AppImage's read-only mount, the
+		// runtime reports the image itself
+		mode, source, target = "appimage", filepath.Join(u.downloadedPath, "arc-scanner.AppImage"), appImage
+		if _, err := os.Stat(source); err != nil {
+			return fmt.Errorf("no AppImage found in update: %w", err)
+		}
+	} else if source, err = tarballRoot(u.downloadedPath); err != nil {
+		return err
+	}
+
+	scriptPath := filepath.Join(os.TempDir(), "arc-scanner-update.sh")
+	if err := os.WriteFile(scriptPath, []byte(updateScript), 0755); err != nil {
+		return fmt.Errorf("failed to write update script: %w", err)
+	}
+
+	cmd := exec.Command("/bin/sh", scriptPath,
+		strconv.Itoa(os.Getpid()), mode, source, target, filepath.Dir(u.downloadedPath))
+	// Own session, so the script outlives the app and its terminal
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start update script: %w", err)
+	}
+
+	return nil
+}
+
+// tarballRoot returns the directory holding the new executable. Release
+// tarballs wrap everything in a single arc-scanner/ directory.
+func tarballRoot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read extracted directory: %w", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "arc-scanner")); err != nil {
+		return "", fmt.Errorf("no arc-scanner executable found in update: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,117 @@
+package updater
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchAsset(t *testing.T) {
+	assets := []GitHubAsset{
+		{Name: "arc-scanner-macos-v1.2.0.zip", BrowserDownloadURL: "macos.zip"},
+		{Name: "arc-scanner-windows-v1.2.0.zip", BrowserDownloadURL: "windows.zip"},
+		{Name: "arc-scanner-linux-v1.2.0.tar.gz", BrowserDownloadURL: "linux.tar.gz"},
+		{Name: "arc-scanner-linux-v1.2.0-x86_64.AppImage", BrowserDownloadURL: "linux.AppImage"},
+	}
+
+	tests := []struct {
+		goos     string
+		appImage bool
+		want     string
+	}{
+		{"darwin", false, "macos.zip"},
+		{"windows", false, "windows.zip"},
+		{"linux", false, "linux.tar.gz"},
+		{"linux", true, "linux.AppImage"},
+		{"freebsd", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := matchAsset(assets, tt.goos, tt.appImage); got != tt.want {
+			t.Errorf("matchAsset(%s, appImage=%v) = %q, want %q", tt.goos, tt.appImage, got, tt.want)
+		}
+	}
+
+	// An AppImage install never falls back to the tarball
+	if got := matchAsset(assets[:3], "linux", true); got != "" {
+		t.Errorf("matchAsset without AppImage asset = %q, want none", got)
+	}
+}
+
+func TestArchiveExt(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/o/r/releases/download/v1/arc-scanner-macos-v1.zip":          ".zip",
+		"https://github.com/o/r/releases/download/v1/arc-scanner-linux-v1.tar.gz":       ".tar.gz",
+		"https://github.com/o/r/releases/download/v1/arc-scanner-linux-v1.tgz?x=1":      ".tar.gz",
+		"https://github.com/o/r/releases/download/v1/arc-scanner-linux-x86_64.AppImage": ".AppImage",
+	}
+	for url, want := range tests {
+		if got := archiveExt(url); got != want {
+			t.Errorf("archiveExt(%s) = %s, want %s", url, got, want)
+		}
+	}
+}
+
+// writeTarGz writes a tarball of regular files, name to content
+func writeTarGz(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "update.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	archive := writeTarGz(t, map[string]string{
+		"arc-scanner/arc-scanner": "binary",
+		"arc-scanner/README.md":   "readme",
+	})
+	dest := filepath.Join(t.TempDir(), "extracted")
+
+	if err := New("o", "r", "1.0.0").extractTarGz(archive, dest); err != nil {
+		t.Fatalf("extractTarGz failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "arc-scanner", "arc-scanner"))
+	if err != nil {
+		t.Fatalf("executable not extracted: %v", err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("executable mode = %v, want it executable", info.Mode())
+	}
+}
+
+func TestExtractTarGz_PathTraversal(t *testing.T) {
+	archive := writeTarGz(t, map[string]string{"../escaped": "x"})
+	dest := filepath.Join(t.TempDir(), "extracted")
+
+	if err := New("o", "r", "1.0.0").extractTarGz(archive, dest); err == nil {
+		t.Error("extractTarGz accepted an entry outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escaped")); err == nil {
+		t.Error("entry was written outside the destination")
+	}
+}
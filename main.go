@@ -2,11 +2,13 @@ package main
 
 import (
 	"embed"
+	"net/http"
 	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/options/linux"
 	"github.com/wailsapp/wails/v2/pkg/options/mac"
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 )
@@ -25,7 +27,8 @@ func main() {
 		Width:  1,
 		Height: 1,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: http.HandlerFunc(app.serveAsset),
 		},
 		BackgroundColour: &options.RGBA{R: 0, G: 0, B: 0, A: 0},
 		Frameless:        true,
@@ -44,9 +47,17 @@ func main() {
 			DisableWindowIcon:                 false,
 			DisableFramelessWindowDecorations: true,
 		},
-		OnStartup: app.startup,
+		Linux: &linux.Options{
+			WindowIsTranslucent: true,
+			// The overlay is static, keep WebKitGTK off the GPU the game uses
+			WebviewGpuPolicy: linux.WebviewGpuPolicyNever,
+			ProgramName:      "arc-scanner",
+		},
+		OnStartup:  app.startup,
+		OnShutdown: app.shutdown,
 		Bind: []interface{}{
 			app,
+			app.inventory,
 		},
 	})
 	if err != nil {
@@ -62,6 +62,10 @@ fi
 echo "  ✓ Found: $MINGW_GCC"
 echo ""
 
+# The embedded item snapshot must be real before it is compiled in
+./scripts/check-snapshot.sh
+echo ""
+
 # Step 1: Download and extract Windows Tesseract
 echo "Step 1/4: Downloading Windows Tesseract binaries..."
 echo "-----------------------------------------------"
@@ -6,6 +6,18 @@ echo Building self-contained Arc Scanner for Windows
 echo ===============================================
 echo.
 
+REM The embedded item snapshot must be real before it is compiled in
+go generate ./internal/items
+if errorlevel 1 (
+    findstr /C:"\"items\":[]" internal\items\snapshot.json >nul
+    if not errorlevel 1 (
+        echo Error: internal\items\snapshot.json is the empty placeholder and could not be generated.
+        exit /b 1
+    )
+    echo   Warning: snapshot refresh failed, keeping the committed snapshot
+)
+echo.
+
 REM Step 1: Bundle Tesseract
 echo Step 1/3: Bundling Tesseract...
 echo -----------------------------------------------
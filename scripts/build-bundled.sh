@@ -4,6 +4,10 @@ set -e
 echo "Building self-contained Arc Scanner..."
 echo ""
 
+# The embedded item snapshot must be real before it is compiled in
+./scripts/check-snapshot.sh
+echo ""
+
 # Step 1: Bundle Tesseract
 echo "Step 1/3: Bundling Tesseract..."
 ./scripts/bundle-tesseract.sh
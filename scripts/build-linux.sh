@@ -0,0 +1,62 @@
+#!/bin/bash
+set -e
+
+echo "Building Arc Scanner for Linux..."
+echo ""
+
+VERSION="${APP_VERSION:-dev}"
+OUT_DIR="${1:-build/release}"
+ARCH="$(uname -m)"
+
+# The embedded item snapshot must be real before it is compiled in
+./scripts/check-snapshot.sh
+echo ""
+
+# Step 1: Build with Wails (needs libgtk-3-dev, libwebkit2gtk-4.0-dev
+# and the X11 headers for the keyboard hook)
+echo "Step 1/3: Building app with Wails..."
+if [ -n "$APP_VERSION" ]; then
+    echo "  Embedding version: $APP_VERSION"
+    wails build -platform "linux/amd64" -ldflags "-X main.Version=$APP_VERSION"
+else
+    wails build -platform "linux/amd64"
+fi
+echo ""
+
+mkdir -p "$OUT_DIR"
+
+# Step 2: Tarball, unpacked anywhere and updated in place. Tesseract
+# comes from the distro, see DEVELOPMENT.md
+echo "Step 2/3: Packaging tarball..."
+STAGE="build/linux/arc-scanner"
+rm -rf build/linux
+mkdir -p "$STAGE"
+cp build/bin/arc-scanner "$STAGE/"
+cp README.md "$STAGE/"
+tar -C build/linux -czf "$OUT_DIR/arc-scanner-linux-v$VERSION.tar.gz" arc-scanner
+echo "  Created: $OUT_DIR/arc-scanner-linux-v$VERSION.tar.gz"
+echo ""
+
+# Step 3: AppImage, only if appimagetool is installed
+echo "Step 3/3: Packaging AppImage..."
+if ! command -v appimagetool &> /dev/null; then
+    echo "  appimagetool not found, skipping AppImage"
+    exit 0
+fi
+
+APPDIR="build/linux/arc-scanner.AppDir"
+mkdir -p "$APPDIR/usr/bin"
+cp build/bin/arc-scanner "$APPDIR/usr/bin/"
+cp build/appicon.png "$APPDIR/arc-scanner.png"
+ln -sf usr/bin/arc-scanner "$APPDIR/AppRun"
+cat > "$APPDIR/arc-scanner.desktop" <<EOF
+[Desktop Entry]
+Type=Application
+Name=Arc Scanner
+Exec=arc-scanner
+Icon=arc-scanner
+Categories=Game;Utility;
+EOF
+
+ARCH="$ARCH" appimagetool "$APPDIR" "$OUT_DIR/arc-scanner-linux-v$VERSION-$ARCH.AppImage"
+echo "  Created: $OUT_DIR/arc-scanner-linux-v$VERSION-$ARCH.AppImage"
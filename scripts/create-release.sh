@@ -14,6 +14,15 @@ export APP_VERSION="$VERSION"
 rm -rf "$RELEASE_DIR"
 mkdir -p "$RELEASE_DIR"
 
+# Refresh the embedded item snapshot (offline fallback). A release never
+# ships an old or placeholder snapshot, so a failed refresh stops it
+echo "=== Refreshing item snapshot ==="
+if ! go generate ./internal/items; then
+    echo "Error: snapshot refresh failed, not creating a release"
+    exit 1
+fi
+echo ""
+
 # Build macOS
 echo "=== Building macOS ==="
 ./scripts/build-bundled.sh
@@ -41,6 +50,24 @@ if [ -f "./scripts/build-bundled-windows-cross.sh" ]; then
 else
     echo "Warning: Windows cross-compile script not found, skipping Windows build"
 fi
+echo ""
+
+# Build Linux natively, or in a container from macOS. Linux users update
+# from these assets, so a release without them stops here
+echo "=== Building Linux ==="
+if [ "$(uname -s)" = "Linux" ]; then
+    ./scripts/build-linux.sh "$RELEASE_DIR"
+elif command -v docker &> /dev/null; then
+    docker build -t arc-scanner-linux-build -f scripts/linux-build.Dockerfile scripts
+    docker run --rm \
+        --user "$(id -u):$(id -g)" \
+        -e HOME=/tmp -e GOPATH=/tmp/go -e APP_VERSION="$APP_VERSION" \
+        -v "$PWD":/src \
+        arc-scanner-linux-build ./scripts/build-linux.sh "$RELEASE_DIR"
+else
+    echo "Error: the Linux build needs a Linux host or Docker, not creating a release"
+    exit 1
+fi
 
 echo ""
 echo "=== Release v$VERSION Complete ==="
@@ -55,9 +82,16 @@ if command -v gh &> /dev/null; then
     read -r response
     if [[ "$response" =~ ^[Yy]$ ]]; then
         echo "Creating GitHub release v$VERSION..."
-        gh release create "v$VERSION" \
-            "$RELEASE_DIR/arc-scanner-macos-v$VERSION.zip" \
-            "$RELEASE_DIR/arc-scanner-windows-v$VERSION.zip" \
+        ASSETS=(
+            "$RELEASE_DIR/arc-scanner-macos-v$VERSION.zip"
+            "$RELEASE_DIR/arc-scanner-windows-v$VERSION.zip"
+            "$RELEASE_DIR/arc-scanner-linux-v$VERSION.tar.gz"
+        )
+        # The AppImage is only there when appimagetool was installed
+        shopt -s nullglob
+        ASSETS+=("$RELEASE_DIR"/arc-scanner-linux-v"$VERSION"-*.AppImage)
+        shopt -u nullglob
+        gh release create "v$VERSION" "${ASSETS[@]}" \
             --title "v$VERSION" \
             --notes "## Installation
 
@@ -71,13 +105,18 @@ if command -v gh &> /dev/null; then
 1. Download \`arc-scanner-windows-v$VERSION.zip\`
 2. Extract to a folder (keep folder structure)
 3. Run \`arc-scanner.exe\`
-4. If SmartScreen appears: Click \"More info\" → \"Run anyway\""
+4. If SmartScreen appears: Click \"More info\" → \"Run anyway\"
+
+### Linux
+1. Install Tesseract from your distribution (e.g. \`sudo apt install tesseract-ocr\`)
+2. Download \`arc-scanner-linux-v$VERSION.tar.gz\` and extract it, or download the AppImage and make it executable
+3. Run \`arc-scanner\`"
         echo "Release created: https://github.com/Mizuba74/arc-scanner/releases/tag/v$VERSION"
     fi
 else
     echo "Next steps:"
     echo "  1. Create a GitHub release at: https://github.com/Mizuba74/arc-scanner/releases/new"
     echo "  2. Tag: v$VERSION"
-    echo "  3. Upload the zip files from $RELEASE_DIR"
+    echo "  3. Upload the zip, tar.gz and AppImage files from $RELEASE_DIR"
     echo "  4. Add release notes with install instructions"
 fi
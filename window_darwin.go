@@ -29,6 +29,22 @@ void setWindowLevelAboveScreensaver() {
     });
 }
 
+// Move the overlay to the top-right corner of a display. right and top
+// are in global display coordinates (top-left origin, points), which
+// Cocoa flips around the main screen's height.
+void moveWindowToDisplay(double right, double top) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        NSArray *windows = [NSApp windows];
+        if ([windows count] == 0) {
+            return;
+        }
+        NSWindow *window = [windows objectAtIndex:0];
+        CGFloat mainHeight = [[[NSScreen screens] objectAtIndex:0] frame].size.height;
+        NSRect frame = [window frame];
+        [window setFrameOrigin:NSMakePoint(right - frame.size.width, mainHeight - top - frame.size.height)];
+    });
+}
+
 // Request both permissions upfront so user only needs one restart
 void requestPermissions() {
     // Request Screen Recording permission (macOS 10.15+)
@@ -67,11 +83,22 @@ int checkPermissions() {
 */
 import "C"
 
+import (
+	"context"
+	"image"
+)
+
 func setWindowAboveFullscreen() {
 	println("Setting window level above fullscreen...")
 	C.setWindowLevelAboveScreensaver()
 }
 
+// moveWindowToDisplay moves the overlay to the top-right corner of a
+// display, given in global display coordinates
+func moveWindowToDisplay(_ context.Context, display image.Rectangle) {
+	C.moveWindowToDisplay(C.double(display.Max.X), C.double(display.Min.Y))
+}
+
 // RequestPermissions prompts for both Screen Recording and Accessibility permissions
 func requestPermissions() {
 	C.requestPermissions()
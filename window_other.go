@@ -2,8 +2,43 @@
 
 package main
 
+import (
+	"context"
+	"image"
+
+	"github.com/kbinani/screenshot"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
 func setWindowAboveFullscreen() {}
 
+// moveWindowToDisplay moves the overlay to the top-right corner of a
+// display, given in X root window pixels. Wails positions windows
+// relative to the monitor they are on, so the target is offset by the
+// monitor the overlay is on now.
+func moveWindowToDisplay(ctx context.Context, display image.Rectangle) {
+	x, y := runtime.WindowGetPosition(ctx)
+	width, height := runtime.WindowGetSize(ctx)
+	current := windowDisplay(image.Rect(x, y, x+width, y+height))
+
+	runtime.WindowSetPosition(ctx, display.Max.X-width-current.Min.X, display.Min.Y-current.Min.Y)
+}
+
+// windowDisplay returns the display a window overlaps most, like GTK
+// picks the window's monitor.
+func windowDisplay(window image.Rectangle) image.Rectangle {
+	var best image.Rectangle
+	bestArea := -1
+	for i := range screenshot.NumActiveDisplays() {
+		display := screenshot.GetDisplayBounds(i)
+		overlap := display.Intersect(window).Size()
+		if area := overlap.X * overlap.Y; area > bestArea {
+			best, bestArea = display, area
+		}
+	}
+	return best
+}
+
 func requestPermissions() {}
 
 func checkPermissions() int { return 0 }
@@ -3,7 +3,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"image"
 	"syscall"
 	"unsafe"
 
@@ -66,6 +68,38 @@ func setWindowAboveFullscreen() {
 	}
 }
 
+// moveWindowToDisplay moves the overlay to the top-right corner of a
+// display, given in virtual desktop pixels. The frontend positions the
+// window relative to whichever monitor it is on, so it stays there.
+func moveWindowToDisplay(_ context.Context, display image.Rectangle) {
+	hwnd := findWindowByTitle("arc-scanner")
+	if hwnd == 0 {
+		println("Warning: Could not find window handle")
+		return
+	}
+
+	var rect win.RECT
+	if !win.GetWindowRect(hwnd, &rect) {
+		return
+	}
+	width := int(rect.Right - rect.Left)
+
+	x := display.Max.X - width
+	y := display.Min.Y
+
+	ret, _, err := procSetWindowPos.Call(
+		uintptr(hwnd),
+		HWND_TOPMOST,
+		uintptr(x), uintptr(y),
+		0, 0,
+		SWP_NOSIZE|SWP_NOACTIVATE,
+	)
+
+	if ret == 0 {
+		fmt.Printf("Error moving window: %v\n", err)
+	}
+}
+
 func findWindowByTitle(title string) win.HWND {
 	titlePtr, _ := syscall.UTF16PtrFromString(title)
 	hwnd, _, _ := procFindWindowW.Call(